@@ -8,12 +8,19 @@ const abilitiesListEl = document.getElementById('abilities-list');
 const saveGameButton = document.getElementById('save-game-button');
 const loadGameButton = document.getElementById('load-game-button');
 
+const savesModal = document.getElementById('saves-modal');
+const savesListEl = document.getElementById('saves-list');
+const closeSavesButton = document.getElementById('close-saves-button');
+
 const newGameModal = document.getElementById('new-game-modal');
 const mainGameContainer = document.getElementById('main-game-container');
 const placementContainer = document.getElementById('placement-container');
 const autoPlaceButton = document.getElementById('auto-place-button');
 const manualPlaceButton = document.getElementById('manual-place-button');
 const cancelNewGameButton = document.getElementById('cancel-new-game-button');
+const difficultySelect = document.getElementById('difficulty-select');
+const presetSelect = document.getElementById('preset-select');
+const modeSelect = document.getElementById('mode-select');
 const placementBoardEl = document.getElementById('placement-board');
 const shipListEl = document.getElementById('ship-list');
 const rotateShipButton = document.getElementById('rotate-ship-button');
@@ -22,10 +29,16 @@ const startManualGameButton = document.getElementById('start-manual-game-button'
 
 let isAnimating = false;
 let selectedAbility = null;
+let shotsPerTurn = 1;
+let salvoMode = false;
+let salvoTargets = [];
 let shipsToPlace = [];
 let placedShips = [];
 let selectedShipToPlace = null;
 let isShipVertical = false;
+let placementRules = null;
+
+const shipNames = { 1: 'Катер', 2: 'Эсминец', 3: 'Крейсер', 4: 'Линкор', 5: 'Авианосец' };
 
 async function updateGameView() {
     try {
@@ -33,15 +46,23 @@ async function updateGameView() {
         if (!response.ok) throw new Error("Не удалось загрузить игру");
         const data = await response.json();
         const gameState = data.game;
-
-        renderBoard(playerBoardEl, gameState.Player1.MyBoard.Grid, false);
-        renderBoard(enemyBoardEl, gameState.Player2.MyBoard.Grid, true);
-        renderAbilities(gameState.Player1.Abilities);
+        salvoMode = gameState.rules.mode === 'salvo';
+        shotsPerTurn = gameState.shots_per_turn;
+        salvoTargets = [];
+
+        renderBoard(playerBoardEl, gameState.me.board.grid, false);
+        renderDefences(playerBoardEl, gameState.me.board);
+        renderBoard(enemyBoardEl, gameState.enemy.board.grid, true);
+        renderAbilities(gameState.me.abilities);
         updateMessage(gameState);
 
         loadGameButton.style.display = data.save_exists ? 'inline-block' : 'none';
         isAnimating = false;
 
+        if (gameState.winner) {
+            handleGameOver(gameState.winner);
+        }
+
     } catch (error) {
         messageAreaEl.textContent = `Ошибка: ${error.message}`;
     }
@@ -49,19 +70,23 @@ async function updateGameView() {
 
 function renderBoard(tableElement, grid, isEnemy) {
     tableElement.innerHTML = '';
-    for (let i = 0; i < 10; i++) {
+    for (let i = 0; i < grid.length; i++) {
         const row = document.createElement('tr');
-        for (let j = 0; j < 10; j++) {
+        for (let j = 0; j < grid[i].length; j++) {
             const cell = document.createElement('td');
             const cellState = grid[i][j];
             switch (cellState) {
-                case 0: cell.className = 'cell-empty'; break;
-                case 1: cell.className = isEnemy ? 'cell-empty' : 'cell-ship'; break;
-                case 2: cell.className = 'cell-miss'; cell.textContent = '•'; break;
-                case 3: cell.className = 'cell-hit'; cell.textContent = '✕'; break;
+                case 'empty': cell.className = 'cell-empty'; break;
+                case 'ship': cell.className = isEnemy ? 'cell-empty' : 'cell-ship'; break;
+                case 'miss': cell.className = 'cell-miss'; cell.textContent = '•'; break;
+                case 'hit': cell.className = 'cell-hit'; cell.textContent = '✕'; break;
             }
-            if (isEnemy && (cellState === 0 || cellState === 1)) {
-                cell.addEventListener('click', () => onEnemyCellClick(i, j));
+            if (isEnemy) {
+                // торпеду можно пустить и с обстрелянной крайней клетки, поэтому клики ловятся везде
+                const untouched = cellState === 'empty' || cellState === 'ship';
+                cell.addEventListener('click', () => onEnemyCellClick(i, j, cell, untouched));
+            } else {
+                cell.addEventListener('click', () => onPlayerCellClick(i, j));
             }
             row.appendChild(cell);
         }
@@ -69,6 +94,16 @@ function renderBoard(tableElement, grid, isEnemy) {
     }
 }
 
+// renderDefences отмечает на своем поле щиты и ложные цели
+function renderDefences(tableElement, board) {
+    for (const p of board.shields || []) {
+        tableElement.rows[p.X].cells[p.Y].classList.add('cell-shield');
+    }
+    for (const p of board.decoys || []) {
+        tableElement.rows[p.X].cells[p.Y].classList.add('cell-decoy');
+    }
+}
+
 function renderAbilities(abilities) {
     abilitiesListEl.innerHTML = '';
     if (!abilities || abilities.length === 0) {
@@ -79,8 +114,10 @@ function renderAbilities(abilities) {
         const button = document.createElement('button');
         button.className = 'ability-button';
         button.textContent = ability.Name;
-        button.dataset.abilityName = ability.Name;
+        button.dataset.abilityId = ability.id;
         button.dataset.requiresTarget = ability.RequiresTarget;
+        button.dataset.requiresDirection = ability.RequiresDirection === true;
+        button.dataset.ownBoard = ability.OwnBoard === true;
         button.addEventListener('click', onAbilityClick);
         abilitiesListEl.appendChild(button);
     });
@@ -88,7 +125,9 @@ function renderAbilities(abilities) {
 
 function updateMessage(gameState) {
     if (selectedAbility) return;
-    if (gameState.CurrentPlayer.Name === 'Player') {
+    if (gameState.is_my_turn && salvoMode) {
+        messageAreaEl.textContent = `Ваш залп: выберите клеток - ${shotsPerTurn}.`;
+    } else if (gameState.is_my_turn) {
         messageAreaEl.textContent = "Ваш ход.";
     } else {
         messageAreaEl.textContent = "Ход компьютера...";
@@ -98,44 +137,134 @@ function updateMessage(gameState) {
 function onAbilityClick(event) {
     if (isAnimating) return;
     const button = event.target;
-    const abilityName = button.dataset.abilityName;
+    const abilityId = button.dataset.abilityId;
+    const abilityName = button.textContent;
     const requiresTarget = button.dataset.requiresTarget === 'true';
+    const requiresDirection = button.dataset.requiresDirection === 'true';
+    const ownBoard = button.dataset.ownBoard === 'true';
 
     if (button.classList.contains('selected')) {
         selectedAbility = null;
         button.classList.remove('selected');
         enemyBoardEl.classList.remove('targeting-mode');
+        playerBoardEl.classList.remove('targeting-mode');
         messageAreaEl.textContent = "Выбор цели отменен. Ваш ход.";
         return;
     }
 
     if (requiresTarget) {
-        selectedAbility = { name: abilityName, button: button };
+        selectedAbility = { id: abilityId, button: button, requiresDirection: requiresDirection, ownBoard: ownBoard };
         document.querySelectorAll('.ability-button').forEach(b => b.classList.remove('selected'));
         button.classList.add('selected');
-        enemyBoardEl.classList.add('targeting-mode');
-        messageAreaEl.textContent = `Выберите цель для способности "${abilityName}"`;
+        enemyBoardEl.classList.remove('targeting-mode');
+        playerBoardEl.classList.remove('targeting-mode');
+        (ownBoard ? playerBoardEl : enemyBoardEl).classList.add('targeting-mode');
+        if (ownBoard) {
+            messageAreaEl.textContent = `Выберите клетку своего поля для способности "${abilityName}"`;
+        } else if (requiresDirection) {
+            messageAreaEl.textContent = `Выберите клетку на краю поля, откуда пойдет "${abilityName}"`;
+        } else {
+            messageAreaEl.textContent = `Выберите цель для способности "${abilityName}"`;
+        }
     } else {
-        useAbility(abilityName);
+        useAbility(abilityId);
     }
 }
 
-function onEnemyCellClick(x, y) {
+// клики по своему полю нужны только защитным способностям
+function onPlayerCellClick(x, y) {
+    if (isAnimating || !selectedAbility || !selectedAbility.ownBoard) return;
+    useAbility(selectedAbility.id, x, y);
+}
+
+function onEnemyCellClick(x, y, cell, untouched) {
     if (isAnimating) return;
-    if (selectedAbility) {
-        useAbility(selectedAbility.name, x, y);
+    if (selectedAbility && selectedAbility.ownBoard) {
+        messageAreaEl.textContent = 'Эта способность применяется к своему полю.';
+    } else if (selectedAbility && selectedAbility.requiresDirection) {
+        const direction = edgeDirection(x, y);
+        if (!direction) {
+            messageAreaEl.textContent = 'Выберите клетку на краю поля.';
+            return;
+        }
+        useAbility(selectedAbility.id, x, y, direction);
+    } else if (!untouched) {
+        return;
+    } else if (selectedAbility) {
+        useAbility(selectedAbility.id, x, y);
+    } else if (salvoMode) {
+        toggleSalvoTarget(x, y, cell);
     } else {
         handleAttack(x, y);
     }
 }
 
+// edgeDirection - направление внутрь поля от крайней клетки; в углу выбирается вдоль строки
+function edgeDirection(x, y) {
+    const rows = enemyBoardEl.rows.length;
+    const cols = enemyBoardEl.rows[0].cells.length;
+    if (y === 0) return 'right';
+    if (y === cols - 1) return 'left';
+    if (x === 0) return 'down';
+    if (x === rows - 1) return 'up';
+    return null;
+}
+
+// в режиме залпа клики отмечают цели, залп уходит, когда отмечено shotsPerTurn клеток
+function toggleSalvoTarget(x, y, cell) {
+    const index = salvoTargets.findIndex(p => p.x === x && p.y === y);
+    if (index !== -1) {
+        salvoTargets.splice(index, 1);
+        cell.classList.remove('cell-salvo');
+    } else {
+        salvoTargets.push({ x: x, y: y });
+        cell.classList.add('cell-salvo');
+    }
+
+    if (salvoTargets.length >= shotsPerTurn) {
+        handleSalvo(salvoTargets);
+        return;
+    }
+    messageAreaEl.textContent = `Залп: выбрано ${salvoTargets.length} из ${shotsPerTurn}.`;
+}
+
+async function handleSalvo(targets) {
+    isAnimating = true;
+    messageAreaEl.textContent = 'Залп!';
+    try {
+        const response = await fetch(`${API_URL}/salvo`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ shots: targets })
+        });
+        const result = await response.json();
+        if (!response.ok) throw new Error(result.message || 'Ошибка залпа');
+        for (const shot of result.shots) {
+            await animateMove(enemyBoardEl, shot);
+        }
+        if (result.game_over) {
+            handleGameOver(result.winner);
+            return;
+        }
+        messageAreaEl.textContent = result.message;
+        if (!result.bot_turn) {
+            await updateGameView();
+        }
+    } catch (error) {
+        messageAreaEl.textContent = `Ошибка: ${error.message}`;
+        salvoTargets = [];
+        enemyBoardEl.querySelectorAll('.cell-salvo').forEach(cell => cell.classList.remove('cell-salvo'));
+        isAnimating = false;
+    }
+}
+
 async function handleAttack(x, y) {
     isAnimating = true;
     messageAreaEl.textContent = `Атакуем клетку (${x}, ${y})...`;
     try {
         const response = await fetch(`${API_URL}/attack?x=${x}&y=${y}`, { method: 'POST' });
         const result = await response.json();
-        if (!response.ok) throw new Error(result.Message || 'Ошибка атаки');
+        if (!response.ok) throw new Error(result.message || 'Ошибка атаки');
         if (result.human_move_result) {
             await animateMove(enemyBoardEl, result.human_move_result);
         }
@@ -144,12 +273,10 @@ async function handleAttack(x, y) {
             return;
         }
         messageAreaEl.textContent = result.message;
-        if (result.computer_moves && result.computer_moves.length > 0) {
+        if (result.bot_turn) {
+            // выстрелы бота придут в потоке событий, ход вернется по turn_switch
             messageAreaEl.textContent = "Ход компьютера...";
-            for (const move of result.computer_moves) {
-                await sleep(800);
-                await animateMove(playerBoardEl, move);
-            }
+            return;
         }
         await updateGameView();
     } catch (error) {
@@ -158,27 +285,78 @@ async function handleAttack(x, y) {
     }
 }
 
-async function useAbility(abilityName, x, y) {
+// события партии анимируются по очереди, в своем темпе, независимо от скорости сервера
+let eventAnimations = Promise.resolve();
+
+function queueAnimation(step) {
+    eventAnimations = eventAnimations.then(step).catch(error => {
+        messageAreaEl.textContent = `Ошибка: ${error.message}`;
+    });
+}
+
+// ensureSession создает игру на сервере, если у браузера ее еще нет или она истекла
+async function ensureSession() {
+    const response = await fetch(`${API_URL}/game`);
+    if (response.status === 404) {
+        await fetch(`${API_URL}/games`, { method: 'POST' });
+    }
+}
+
+function subscribeToEvents() {
+    const events = new EventSource(`${API_URL}/events`);
+
+    events.addEventListener('bot_shot', (e) => {
+        const event = JSON.parse(e.data);
+        queueAnimation(async () => {
+            await sleep(500);
+            await animateMove(playerBoardEl, event.attack);
+        });
+    });
+
+    events.addEventListener('turn_switch', (e) => {
+        const event = JSON.parse(e.data);
+        if (event.player === 'Player') {
+            queueAnimation(updateGameView);
+        }
+    });
+
+    events.addEventListener('game_over', (e) => {
+        const event = JSON.parse(e.data);
+        queueAnimation(() => handleGameOver(event.winner));
+    });
+
+    events.addEventListener('game_reset', () => queueAnimation(updateGameView));
+}
+
+async function useAbility(abilityId, x, y, direction) {
     isAnimating = true;
-    let url = `${API_URL}/ability?ability_name=${abilityName}`;
+    let url = `${API_URL}/ability?ability_name=${abilityId}`;
     if (x !== undefined && y !== undefined) {
         url += `&x=${x}&y=${y}`;
     }
+    if (direction) {
+        url += `&direction=${direction}`;
+    }
+    const ownBoard = selectedAbility !== null && selectedAbility.ownBoard;
     if (selectedAbility) {
         selectedAbility.button.classList.remove('selected');
         enemyBoardEl.classList.remove('targeting-mode');
+        playerBoardEl.classList.remove('targeting-mode');
         selectedAbility = null;
     }
     try {
         const response = await fetch(url, { method: 'POST' });
         const result = await response.json();
-        if (!response.ok) throw new Error(result.Message || 'Ошибка способности');
+        if (!response.ok) throw new Error(result.message || 'Ошибка способности');
 
         messageAreaEl.textContent = result.message;
-        if (result.attack_result) {
-            await animateMove(enemyBoardEl, result.attack_result);
+        if (direction) {
+            await animatePath(enemyBoardEl, result.affected_points);
         }
-        if (result.affected_points) {
+        for (const attack of result.attack_results || []) {
+            await animateMove(enemyBoardEl, attack);
+        }
+        if (result.affected_points && !direction && !ownBoard && !result.attack_results) {
             await animateScan(enemyBoardEl, result.affected_points);
         }
 
@@ -209,14 +387,27 @@ async function animateScan(boardElement, points) {
     await sleep(4000);
 }
 
+// animatePath проводит торпеду по клеткам пути одну за другой
+async function animatePath(boardElement, points) {
+    if (!points) return;
+
+    for (const p of points) {
+        const cell = boardElement.rows[p.X].cells[p.Y];
+        cell.classList.add('cell-torpedo');
+        await sleep(120);
+    }
+    await sleep(300);
+}
+
 async function animateMove(boardElement, moveData) {
     if (!moveData) return;
 
     const move = {
-        x: moveData.x ?? moveData.Target?.X,
-        y: moveData.y ?? moveData.Target?.Y,
-        result: moveData.result ?? moveData.Result,
-        marked_points: moveData.marked_points ?? moveData.MarkedPoints
+        x: moveData.x ?? moveData.target?.X,
+        y: moveData.y ?? moveData.target?.Y,
+        result: moveData.result,
+        marked_points: moveData.marked_points,
+        bonus_hit: moveData.bonus_hit
     };
 
     if (move.x === undefined || move.y === undefined) {
@@ -227,14 +418,27 @@ async function animateMove(boardElement, moveData) {
     cell.classList.add('cell-attacked');
     await sleep(200);
 
-    if (move.result === 0) {
+    if (move.result === 'blocked') {
+        // щит отразил выстрел: клетка остается необстрелянной
+        cell.classList.remove('cell-attacked');
+        cell.classList.add('cell-blocked');
+        await sleep(400);
+        cell.classList.remove('cell-blocked');
+        return;
+    } else if (move.result === 'miss') {
         cell.className = 'cell-miss'; cell.textContent = '•';
     } else {
         cell.className = 'cell-hit'; cell.textContent = '✕';
     }
     await sleep(200);
 
-    if (move.result === 2 && move.marked_points) {
+    if (move.bonus_hit) {
+        const bonusCell = boardElement.rows[move.bonus_hit.X].cells[move.bonus_hit.Y];
+        bonusCell.className = 'cell-hit'; bonusCell.textContent = '✕';
+        await sleep(200);
+    }
+
+    if (move.result === 'sunk' && move.marked_points) {
         messageAreaEl.textContent = "Потопил!";
         for (const p of move.marked_points) {
             const markedCell = boardElement.rows[p.X].cells[p.Y];
@@ -252,19 +456,19 @@ function handleGameOver(winner) {
     isAnimating = true;
 }
 
+async function loadPlacementRules() {
+    const response = await fetch(`${API_URL}/rules`);
+    const data = await response.json();
+    placementRules = data.presets[presetSelect.value] || data.default;
+}
+
 function initializePlacementState() {
-    shipsToPlace = [
-        { id: 0, size: 4, name: 'Линкор (4)' },
-        { id: 1, size: 3, name: 'Крейсер 1 (3)' },
-        { id: 2, size: 3, name: 'Крейсер 2 (3)' },
-        { id: 3, size: 2, name: 'Эсминец 1 (2)' },
-        { id: 4, size: 2, name: 'Эсминец 2 (2)' },
-        { id: 5, size: 2, name: 'Эсминец 3 (2)' },
-        { id: 6, size: 1, name: 'Катер 1 (1)' },
-        { id: 7, size: 1, name: 'Катер 2 (1)' },
-        { id: 8, size: 1, name: 'Катер 3 (1)' },
-        { id: 9, size: 1, name: 'Катер 4 (1)' }
-    ];
+    const counters = {};
+    shipsToPlace = [...placementRules.fleet].sort((a, b) => b - a).map((size, id) => {
+        counters[size] = (counters[size] || 0) + 1;
+        const name = shipNames[size] || 'Корабль';
+        return { id, size, name: `${name} ${counters[size]} (${size})` };
+    });
     placedShips = [];
     selectedShipToPlace = null;
     isShipVertical = false;
@@ -275,7 +479,8 @@ function initializePlacementState() {
 
 function renderPlacementBoard() {
     placementBoardEl.innerHTML = '';
-    let grid = Array(10).fill(0).map(() => Array(10).fill(0));
+    const { width, height } = placementRules;
+    let grid = Array(height).fill(0).map(() => Array(width).fill(0));
 
     placedShips.forEach(ship => {
         ship.Position.forEach(p => {
@@ -283,9 +488,9 @@ function renderPlacementBoard() {
         });
     });
 
-    for (let i = 0; i < 10; i++) {
+    for (let i = 0; i < height; i++) {
         const row = document.createElement('tr');
-        for (let j = 0; j < 10; j++) {
+        for (let j = 0; j < width; j++) {
             const cell = document.createElement('td');
             if (grid[i][j] === 1) {
                 cell.className = 'cell-ship';
@@ -324,22 +529,24 @@ function renderShipList() {
 }
 
 function getShipPointsAndValidation(startX, startY, size, isVertical) {
+    const { width, height, allow_touching } = placementRules;
+    const reach = allow_touching ? 0 : 1;
     let points = [];
     let isValid = true;
     for (let i = 0; i < size; i++) {
         const x = isVertical ? startX + i : startX;
         const y = isVertical ? startY : startY + i;
         points.push({ x, y });
-        if (x >= 10 || y >= 10) isValid = false;
+        if (x >= height || y >= width) isValid = false;
     }
     if (!isValid) return { points, isValid };
 
     for (const p of points) {
-        for (let dx = -1; dx <= 1; dx++) {
-            for (let dy = -1; dy <= 1; dy++) {
+        for (let dx = -reach; dx <= reach; dx++) {
+            for (let dy = -reach; dy <= reach; dy++) {
                 const checkX = p.x + dx;
                 const checkY = p.y + dy;
-                if (checkX >= 0 && checkX < 10 && checkY >= 0 && checkY < 10) {
+                if (checkX >= 0 && checkX < height && checkY >= 0 && checkY < width) {
                     if (placementBoardEl.rows[checkX].cells[checkY].classList.contains('cell-ship')) {
                         isValid = false;
                         break;
@@ -411,11 +618,12 @@ autoPlaceButton.addEventListener('click', async () => {
     newGameModal.style.display = 'none';
     mainGameContainer.style.display = 'flex';
     placementContainer.style.display = 'none';
-    await fetch(`${API_URL}/newgame/auto`, { method: 'POST' });
+    await fetch(`${API_URL}/newgame/auto?difficulty=${difficultySelect.value}&preset=${presetSelect.value}&mode=${modeSelect.value}`, { method: 'POST' });
     await updateGameView();
 });
 
-manualPlaceButton.addEventListener('click', () => {
+manualPlaceButton.addEventListener('click', async () => {
+    await loadPlacementRules();
     newGameModal.style.display = 'none';
     mainGameContainer.style.display = 'none';
     placementContainer.style.display = 'flex';
@@ -438,13 +646,13 @@ startManualGameButton.addEventListener('click', async () => {
         }))
     };
     try {
-        const response = await fetch(`${API_URL}/newgame/manual`, {
+        const response = await fetch(`${API_URL}/newgame/manual?difficulty=${difficultySelect.value}&preset=${presetSelect.value}&mode=${modeSelect.value}`, {
             method: 'POST',
             headers: { 'Content-Type': 'application/json' },
             body: JSON.stringify(payload)
         });
         const result = await response.json();
-        if (!response.ok) throw new Error(result.Message || 'Ошибка создания игры');
+        if (!response.ok) throw new Error(result.message || 'Ошибка создания игры');
         placementContainer.style.display = 'none';
         mainGameContainer.style.display = 'flex';
         await updateGameView();
@@ -453,15 +661,66 @@ startManualGameButton.addEventListener('click', async () => {
     }
 });
 
-saveGameButton.addEventListener('click', () => {
+saveGameButton.addEventListener('click', async () => {
     if (isAnimating) return;
-    fetch(`${API_URL}/save`, { method: 'POST' });
+    const slot = prompt('Название сохранения (буквы, цифры, - и _):', 'default');
+    if (slot === null) return;
+
+    const response = await fetch(`${API_URL}/save?slot=${encodeURIComponent(slot)}`, { method: 'POST' });
+    const data = await response.json();
+    messageAreaEl.textContent = response.ok ? `${data.message}: ${data.slot}` : `Ошибка: ${data.message}`;
+    loadGameButton.style.display = response.ok ? 'inline-block' : loadGameButton.style.display;
 });
 
+async function renderSaves() {
+    const response = await fetch(`${API_URL}/saves`);
+    const data = await response.json();
+    savesListEl.innerHTML = '<tr><th>Слот</th><th>Сохранено</th><th>Ходов</th><th>Корабли</th><th></th></tr>';
+
+    for (const save of data.saves) {
+        const row = document.createElement('tr');
+        const ships = Object.entries(save.ships_left).map(([name, left]) => `${name}: ${left}`).join(', ');
+        row.innerHTML = `<td></td><td>${new Date(save.saved_at).toLocaleString()}</td><td>${save.turns}</td><td></td><td></td>`;
+        row.cells[0].textContent = save.slot;
+        row.cells[3].textContent = ships;
+
+        const loadButton = document.createElement('button');
+        loadButton.textContent = 'Загрузить';
+        loadButton.addEventListener('click', async () => {
+            const shared = save.shared ? '&shared=true' : '';
+            await fetch(`${API_URL}/load?slot=${encodeURIComponent(save.slot)}${shared}`, { method: 'POST' });
+            savesModal.style.display = 'none';
+            updateGameView();
+        });
+
+        const deleteButton = document.createElement('button');
+        deleteButton.textContent = 'Удалить';
+        deleteButton.addEventListener('click', async () => {
+            if (!confirm(`Удалить сохранение ${save.slot}?`)) return;
+            await fetch(`${API_URL}/saves?slot=${encodeURIComponent(save.slot)}`, { method: 'DELETE' });
+            renderSaves();
+        });
+
+        // общие слоты, перенесенные со старой версии, можно только загрузить
+        row.cells[4].append(loadButton);
+        if (!save.shared) row.cells[4].append(deleteButton);
+        savesListEl.appendChild(row);
+    }
+}
+
 loadGameButton.addEventListener('click', async () => {
     if (isAnimating) return;
-    await fetch(`${API_URL}/load`, { method: 'POST' });
+    await renderSaves();
+    savesModal.style.display = 'flex';
+});
+
+closeSavesButton.addEventListener('click', () => {
+    savesModal.style.display = 'none';
     updateGameView();
 });
 
-document.addEventListener('DOMContentLoaded', updateGameView);
\ No newline at end of file
+document.addEventListener('DOMContentLoaded', async () => {
+    await ensureSession();
+    updateGameView();
+    subscribeToEvents();
+});
\ No newline at end of file
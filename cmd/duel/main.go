@@ -0,0 +1,137 @@
+// duel - тестовый клиент, который создает партию в лобби и играет за обоих игроков
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"sea_battle/game"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+type seatInfo struct {
+	MatchID string     `json:"match_id"`
+	Seat    int        `json:"seat"`
+	Token   string     `json:"token"`
+	Rules   game.Rules `json:"rules"`
+}
+
+type serverMessage struct {
+	Type  string         `json:"type"`
+	Phase string         `json:"phase"`
+	Seat  int            `json:"seat"`
+	Game  *game.GameView `json:"game"`
+	Move  *struct {
+		Player string            `json:"player"`
+		X      int               `json:"x"`
+		Y      int               `json:"y"`
+		Result game.AttackResult `json:"result"`
+	} `json:"move"`
+	Winner  string `json:"winner"`
+	Message string `json:"message"`
+}
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "адрес сервера")
+	preset := flag.String("preset", "classic", "набор правил: classic, quick или big")
+	mode := flag.String("mode", "classic", "режим: classic или salvo")
+	flag.Parse()
+
+	first := postSeat(fmt.Sprintf("http://%s/api/lobby?name=%s&preset=%s&mode=%s", *addr, url.QueryEscape("Алиса"), *preset, *mode))
+	second := postSeat(fmt.Sprintf("http://%s/api/lobby/join?match=%s&name=%s", *addr, first.MatchID, url.QueryEscape("Боб")))
+	log.Printf("Создана партия %s", first.MatchID)
+
+	var wg sync.WaitGroup
+	for _, seat := range []seatInfo{first, second} {
+		wg.Add(1)
+		go func(seat seatInfo) {
+			defer wg.Done()
+			playSeat(*addr, seat)
+		}(seat)
+	}
+	wg.Wait()
+}
+
+func postSeat(rawURL string) seatInfo {
+	resp, err := http.Post(rawURL, "application/json", nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("%s: статус %d", rawURL, resp.StatusCode)
+	}
+
+	var info seatInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		log.Fatal(err)
+	}
+	return info
+}
+
+func playSeat(addr string, seat seatInfo) {
+	wsURL := fmt.Sprintf("ws://%s/api/ws?match=%s&token=%s", addr, seat.MatchID, seat.Token)
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer conn.Close()
+
+	board := game.NewBoard(seat.Rules)
+	if err := board.PlaceBoard(rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))); err != nil {
+		log.Fatal(err)
+	}
+	if err := conn.WriteJSON(map[string]interface{}{"type": "place", "ships": board.Ships}); err != nil {
+		log.Fatal(err)
+	}
+
+	for {
+		var msg serverMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			log.Printf("Место %d: соединение закрыто: %v", seat.Seat, err)
+			return
+		}
+
+		switch msg.Type {
+		case "state":
+			if msg.Phase == "playing" && msg.Game != nil && msg.Game.IsMyTurn {
+				targets := randomTargets(msg.Game.Enemy.Board, msg.Game.ShotsPerTurn)
+				if msg.Game.Rules.Mode == game.ModeSalvo {
+					conn.WriteJSON(map[string]interface{}{"type": "salvo", "shots": targets})
+				} else {
+					conn.WriteJSON(map[string]interface{}{"type": "attack", "x": targets[0].X, "y": targets[0].Y})
+				}
+			}
+		case "move":
+			if seat.Seat == 0 {
+				log.Printf("%s стреляет в (%d, %d): %v", msg.Move.Player, msg.Move.X, msg.Move.Y, msg.Move.Result)
+			}
+		case "game_over":
+			log.Printf("Место %d: %s", seat.Seat, msg.Message)
+			return
+		case "error":
+			log.Printf("Место %d: ошибка: %s", seat.Seat, msg.Message)
+		}
+	}
+}
+
+// randomTargets выбирает n разных клеток, по которым еще не стреляли
+func randomTargets(enemy game.BoardView, n int) []game.Point {
+	var targets []game.Point
+	for i := range enemy.Grid {
+		for j, cell := range enemy.Grid[i] {
+			if cell == game.EmptyCell {
+				targets = append(targets, game.Point{X: i, Y: j})
+			}
+		}
+	}
+	rand.Shuffle(len(targets), func(i, j int) { targets[i], targets[j] = targets[j], targets[i] })
+	return targets[:min(n, len(targets))]
+}
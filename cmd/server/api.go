@@ -0,0 +1,318 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sea_battle/game"
+	"sea_battle/i18n"
+	"strconv"
+	"strings"
+)
+
+// Запросы. Параметры строки запроса описываются тегом query:"имя[,required]" и тегом doc,
+// из тех же тегов строится описание параметров в /api/openapi.json
+
+type SlotRequest struct {
+	Slot string `query:"slot" doc:"имя слота сохранения, по умолчанию default"`
+}
+
+type LoadRequest struct {
+	SlotRequest
+	Shared bool `query:"shared" doc:"загрузить общий слот, а не свой"`
+}
+
+type CoordsRequest struct {
+	X int `query:"x,required" doc:"строка клетки"`
+	Y int `query:"y,required" doc:"столбец клетки"`
+}
+
+type AbilityRequest struct {
+	AbilityName string `query:"ability_name,required" doc:"ID способности или ее название на языке запроса"`
+	X           *int   `query:"x" doc:"строка цели, если способности нужна цель"`
+	Y           *int   `query:"y" doc:"столбец цели, если способности нужна цель"`
+	Direction   string `query:"direction" doc:"направление от цели для торпеды: up, down, left или right"`
+}
+
+type NewGameRequest struct {
+	Difficulty string `query:"difficulty" doc:"сложность бота: easy, medium или hard"`
+	Preset     string `query:"preset" doc:"набор правил: classic, quick или big"`
+	Width      *int   `query:"width" doc:"ширина поля вместо заданной набором"`
+	Height     *int   `query:"height" doc:"высота поля вместо заданной набором"`
+	Fleet      string `query:"fleet" doc:"размеры кораблей через запятую, например 4,3,3,2"`
+	Touching   *bool  `query:"touching" doc:"разрешено ли кораблям касаться"`
+	Mode       string `query:"mode" doc:"режим: classic - по выстрелу, salvo - залпами по числу своих кораблей"`
+	Seed       uint64 `query:"seed" doc:"зерно генератора для воспроизводимой партии, 0 - случайное"`
+}
+
+type LobbyCreateRequest struct {
+	Name string `query:"name" doc:"имя создателя партии"`
+	NewGameRequest
+}
+
+type LobbyJoinRequest struct {
+	Match string `query:"match,required" doc:"ID партии"`
+	Name  string `query:"name" doc:"имя второго игрока"`
+}
+
+type WSRequest struct {
+	Match string `query:"match,required" doc:"ID партии"`
+	Token string `query:"token,required" doc:"токен места, выданный при создании или входе"`
+}
+
+type HistoryRequest struct {
+	At *int `query:"at" doc:"вернуть состояние партии после первых at событий журнала"`
+}
+
+type ImportRequest struct {
+	Code string `query:"code,required" doc:"код позиции из /api/game/export"`
+}
+
+type SalvoRequest struct {
+	Shots []game.Point `json:"shots"` // ровно shots_per_turn из /api/game, или все оставшиеся клетки, если их меньше
+}
+
+type ShipPlacementPayload struct {
+	Ships []game.Ship `json:"ships"`
+}
+
+// Ответы
+
+type ErrorResponse struct {
+	Code     ErrorCode           `json:"code"`
+	Message  string              `json:"message"`          // общее описание ошибки на языке запроса
+	Detail   string              `json:"detail,omitempty"` // подробности для разработчиков, без перевода
+	Problems []game.FleetProblem `json:"problems,omitempty"`
+}
+
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+type GamesResponse struct {
+	Games []SessionInfo `json:"games"`
+}
+
+type CreateGameResponse struct {
+	Message string `json:"message"`
+	GameID  string `json:"game_id"`
+}
+
+type GameStatusResponse struct {
+	Game       *game.GameView `json:"game"`
+	GameID     string         `json:"game_id"`
+	SaveExists bool           `json:"save_exists"`
+}
+
+type SlotResponse struct {
+	Message string `json:"message"`
+	Slot    string `json:"slot"`
+}
+
+type SavesResponse struct {
+	Saves []SaveInfo `json:"saves"`
+}
+
+type MoveResult struct {
+	X            int               `json:"x"`
+	Y            int               `json:"y"`
+	Result       game.AttackResult `json:"result"`
+	MarkedPoints []game.Point      `json:"marked_points,omitempty"`
+	DoubleDamage bool              `json:"double_damage,omitempty"`
+	BonusHit     *game.Point       `json:"bonus_hit,omitempty"`
+}
+
+func newMoveResult(attack *game.AttackResultData) MoveResult {
+	return MoveResult{
+		X:            attack.Target.X,
+		Y:            attack.Target.Y,
+		Result:       attack.Result,
+		MarkedPoints: attack.MarkedPoints,
+		DoubleDamage: attack.DoubleDamage,
+		BonusHit:     attack.BonusHit,
+	}
+}
+
+type AttackResponse struct {
+	Message         string     `json:"message"`
+	GameOver        bool       `json:"game_over"`
+	Winner          string     `json:"winner,omitempty"`
+	BotTurn         bool       `json:"bot_turn"` // ход перешел к боту, его выстрелы придут в /api/events
+	HumanMoveResult MoveResult `json:"human_move_result"`
+}
+
+type SalvoResponse struct {
+	Message  string       `json:"message"`
+	Shots    []MoveResult `json:"shots"` // клетки, открытые раньше в том же залпе, пропускаются
+	GameOver bool         `json:"game_over"`
+	Winner   string       `json:"winner,omitempty"`
+	BotTurn  bool         `json:"bot_turn"`
+}
+
+type AbilitiesResponse struct {
+	Abilities []game.AbilityDTO `json:"abilities"`
+}
+
+type AbilityResponse struct {
+	Message        string                   `json:"message"`
+	AffectedPoints []game.Point             `json:"affected_points,omitempty"`
+	AttackResults  []*game.AttackResultData `json:"attack_results,omitempty"`
+	GameOver       bool                     `json:"game_over"`
+	Winner         string                   `json:"winner,omitempty"`
+}
+
+func newAbilityResponse(result *game.AbilityResult, lang i18n.Lang) AbilityResponse {
+	return AbilityResponse{
+		Message:        lang.Format(result.Message),
+		AffectedPoints: result.AffectedPoints,
+		AttackResults:  result.AttackResults,
+	}
+}
+
+type RulesResponse struct {
+	Presets map[string]game.Rules `json:"presets"`
+	Default game.Rules            `json:"default"`
+}
+
+// HistoryResponse - без параметра at приходит журнал events, с ним - состояние партии game
+type HistoryResponse struct {
+	Total  int            `json:"total"`
+	Events []game.Event   `json:"events,omitempty"`
+	At     *int           `json:"at,omitempty"`
+	Game   *game.GameView `json:"game,omitempty"`
+}
+
+type ExportResponse struct {
+	Code  string `json:"code,omitempty"` // вся партия, включая флот бота: после конца игры или с флагом -debug
+	Board string `json:"board"`          // только поле игрока
+}
+
+type LobbyResponse struct {
+	Matches []MatchInfo `json:"matches"`
+}
+
+type SeatResponse struct {
+	MatchID string     `json:"match_id"`
+	Seat    int        `json:"seat"`
+	Token   string     `json:"token"`
+	Rules   game.Rules `json:"rules"`
+}
+
+// requestLang - язык ответа: параметр lang, а без него заголовок Accept-Language
+func requestLang(r *http.Request) i18n.Lang {
+	if lang, ok := i18n.Parse(r.URL.Query().Get("lang")); ok {
+		return lang
+	}
+	return i18n.Negotiate(r.Header.Get("Accept-Language"))
+}
+
+// decodeQuery заполняет структуру запроса из строки запроса по тегам query
+func decodeQuery(r *http.Request, dst interface{}) error {
+	query := r.URL.Query()
+	return forEachQueryField(reflect.ValueOf(dst).Elem(), func(field reflect.Value, name string, required bool) error {
+		str := query.Get(name)
+		if str == "" {
+			if required {
+				return fmt.Errorf("%w: параметр '%s' обязателен", errBadParam, name)
+			}
+			return nil
+		}
+
+		if field.Kind() == reflect.Pointer {
+			field.Set(reflect.New(field.Type().Elem()))
+			field = field.Elem()
+		}
+
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(str)
+		case reflect.Int:
+			n, err := strconv.Atoi(str)
+			if err != nil {
+				return fmt.Errorf("%w: параметр %s должен быть числом", errBadParam, name)
+			}
+			field.SetInt(int64(n))
+		case reflect.Uint64:
+			n, err := strconv.ParseUint(str, 10, 64)
+			if err != nil {
+				return fmt.Errorf("%w: параметр %s должен быть неотрицательным числом", errBadParam, name)
+			}
+			field.SetUint(n)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(str)
+			if err != nil {
+				return fmt.Errorf("%w: параметр %s должен быть true или false", errBadParam, name)
+			}
+			field.SetBool(b)
+		default:
+			return fmt.Errorf("параметр %s: неподдерживаемый тип %s", name, field.Type())
+		}
+		return nil
+	})
+}
+
+// forEachQueryField обходит поля с тегом query, раскрывая встроенные структуры
+func forEachQueryField(v reflect.Value, fn func(field reflect.Value, name string, required bool) error) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if err := forEachQueryField(v.Field(i), fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		tag, ok := f.Tag.Lookup("query")
+		if !ok {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if err := fn(v.Field(i), name, opts == "required"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Options собирает параметры новой партии: сложность, правила и зерно
+func (req NewGameRequest) Options() (game.Options, error) {
+	var opts game.Options
+
+	level, err := game.ParseDifficulty(req.Difficulty)
+	if err != nil {
+		return opts, err
+	}
+	opts.Difficulty = level
+	opts.Seed = req.Seed
+
+	rules, err := game.PresetRules(req.Preset)
+	if err != nil {
+		return opts, err
+	}
+	if req.Width != nil {
+		rules.Width = *req.Width
+	}
+	if req.Height != nil {
+		rules.Height = *req.Height
+	}
+	if req.Fleet != "" {
+		rules.Fleet = nil
+		for _, part := range strings.Split(req.Fleet, ",") {
+			size, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return opts, fmt.Errorf("%w: неверный состав флота %s", game.ErrInvalidRules, req.Fleet)
+			}
+			rules.Fleet = append(rules.Fleet, size)
+		}
+	}
+	if req.Touching != nil {
+		rules.AllowTouching = *req.Touching
+	}
+	if req.Mode != "" {
+		rules.Mode = game.GameMode(req.Mode)
+	}
+
+	opts.Rules = rules
+	return opts, rules.Validate()
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sea_battle/game"
+	"time"
+)
+
+// пауза перед каждым выстрелом бота. Выдерживается без блокировки сессии,
+// поэтому остальные запросы к партии не ждут, а клиенты видят ходы в потоке событий по одному
+const botShotDelay = 300 * time.Millisecond
+
+// botJob - ход бота в конкретной партии; ctx отменяется, если партию заменили или сессия истекла
+type botJob struct {
+	ctx  context.Context
+	game *game.Game
+}
+
+// startBotTurn ставит ход бота в очередь воркера сессии и отменяет предыдущий, если он еще идет.
+// Вызывается под s.mu
+func (s *Session) startBotTurn() {
+	s.cancelBotTurn()
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.botCancel = cancel
+
+	// в очереди может лежать только что отмененный ход, который воркер еще не забрал
+	select {
+	case <-s.botJobs:
+	default:
+	}
+	s.botJobs <- botJob{ctx: ctx, game: s.Game}
+}
+
+// cancelBotTurn прерывает идущий ход бота. Вызывается под s.mu
+func (s *Session) cancelBotTurn() {
+	if s.botCancel != nil {
+		s.botCancel()
+		s.botCancel = nil
+	}
+}
+
+// runBotWorker - воркер сессии: по одному выполняет ходы бота, пока сессия жива
+func (s *Session) runBotWorker() {
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case job := <-s.botJobs:
+			s.playBotTurn(job)
+		}
+	}
+}
+
+// playBotTurn стреляет за бота, пока он попадает. Каждый выстрел делается под блокировкой сессии,
+// а между выстрелами блокировка отпускается
+func (s *Session) playBotTurn(job botJob) {
+	for {
+		select {
+		case <-job.ctx.Done():
+			return
+		case <-time.After(botShotDelay):
+		}
+
+		if done := s.botShot(job); done {
+			return
+		}
+	}
+}
+
+func (s *Session) botShot(job botJob) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g := job.game
+	if job.ctx.Err() != nil || s.Game != g || g.IsOver() || g.CurrentPlayer != g.Player2 {
+		return true
+	}
+
+	if g.Rules.Mode == game.ModeSalvo {
+		return s.botSalvo(g)
+	}
+
+	attack, err := g.HandleComputerTurn()
+	if err != nil {
+		log.Printf("Ошибка в ходе бота: %v", err)
+		return true
+	}
+	log.Printf("Ход компьютера: %+v, Результат: %v", attack.Target, attack.Result)
+
+	if _, over := g.CheckGameOver(); over {
+		return true
+	}
+	if !attack.Result.KeepsTurn() {
+		g.SwitchPlayer()
+		return true
+	}
+	return false
+}
+
+// botSalvo - весь залп бота за один шаг воркера, после него ход переходит. Вызывается под s.mu
+func (s *Session) botSalvo(g *game.Game) bool {
+	attacks, err := g.HandleComputerSalvo()
+	if err != nil {
+		log.Printf("Ошибка в залпе бота: %v", err)
+		return true
+	}
+	for _, attack := range attacks {
+		log.Printf("Залп компьютера: %+v, Результат: %v", attack.Target, attack.Result)
+	}
+
+	if _, over := g.CheckGameOver(); !over {
+		g.SwitchPlayer()
+	}
+	return true
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"sea_battle/game"
+	"sea_battle/i18n"
+)
+
+// ошибки сервера, которых нет в пакете game
+var (
+	errBadParam          = errors.New("ошибка в параметрах запроса")
+	errMethodNotAllowed  = errors.New("метод не разрешен")
+	errMatchNotFound     = errors.New("партия не найдена")
+	errSessionNotFound   = errors.New("игра не найдена: начните новую")
+	errTooManySessions   = errors.New("слишком много одновременных игр")
+	errMatchFull         = errors.New("в партии уже два игрока")
+	errBadToken          = errors.New("неверный токен игрока")
+	errGameNotStarted    = errors.New("игра не идет")
+	errGameStarted       = errors.New("игра уже началась")
+	errNotAgainstBot     = errors.New("код описывает партию двух людей, а не игру против бота")
+	errUnknownWSMessage  = errors.New("неизвестный тип сообщения")
+	errStreamUnsupported = errors.New("сервер не поддерживает потоковую передачу")
+	errUnsignedLegacy    = errors.New("старое сохранение не подписано: перенос с ключом подписи нужно разрешить флагом -import-unsigned")
+)
+
+// apiError - постоянный код ошибки для клиентов и HTTP-статус ответа с ней
+type apiError struct {
+	err    error
+	code   string
+	status int
+}
+
+// apiErrors просматриваются по порядку, побеждает первое совпадение errors.Is, поэтому более
+// частные ошибки стоят раньше общих: *game.FleetError совпадает и с ErrOutOfBounds,
+// а поддельное сохранение - и с ErrCorruptSave
+var apiErrors = []apiError{
+	{errBadParam, "bad_parameter", http.StatusBadRequest},
+	{errMethodNotAllowed, "method_not_allowed", http.StatusMethodNotAllowed},
+	{errMatchNotFound, "match_not_found", http.StatusNotFound},
+	{errSessionNotFound, "session_not_found", http.StatusNotFound},
+	{errTooManySessions, "too_many_sessions", http.StatusServiceUnavailable},
+	{errMatchFull, "match_full", http.StatusConflict},
+	{errBadToken, "bad_token", http.StatusForbidden},
+	{errGameNotStarted, "game_not_started", http.StatusConflict},
+	{errGameStarted, "game_started", http.StatusConflict},
+	{errNotAgainstBot, "not_against_bot", http.StatusUnprocessableEntity},
+	{errUnknownWSMessage, "unknown_message", http.StatusBadRequest},
+
+	{game.ErrGameOver, "game_over", http.StatusConflict},
+	{game.ErrNotYourTurn, "not_your_turn", http.StatusConflict},
+	{game.ErrNotBotTurn, "not_bot_turn", http.StatusConflict},
+	{game.ErrInvalidFleet, "invalid_fleet", http.StatusUnprocessableEntity},
+	{game.ErrOutOfBounds, "out_of_bounds", http.StatusBadRequest},
+	{game.ErrAlreadyShot, "already_shot", http.StatusConflict},
+	{game.ErrNoSuchAbility, "no_such_ability", http.StatusNotFound},
+	{game.ErrWrongMode, "wrong_mode", http.StatusConflict},
+	{game.ErrShotCount, "wrong_shot_count", http.StatusBadRequest},
+	{game.ErrNeedTarget, "target_required", http.StatusBadRequest},
+	{game.ErrBadTarget, "invalid_target", http.StatusBadRequest},
+	{game.ErrShipsTouching, "ships_touching", http.StatusUnprocessableEntity},
+	{game.ErrShipsOverlap, "ships_overlap", http.StatusUnprocessableEntity},
+	{game.ErrInvalidRules, "invalid_rules", http.StatusBadRequest},
+	{game.ErrUnknownDifficulty, "unknown_difficulty", http.StatusBadRequest},
+	{game.ErrNoHistory, "no_history", http.StatusNotFound},
+	{game.ErrInvalidStep, "invalid_step", http.StatusBadRequest},
+
+	{game.ErrSaveNotFound, "save_not_found", http.StatusNotFound},
+	{game.ErrInvalidSlot, "invalid_slot", http.StatusBadRequest},
+	{game.ErrBadSignature, "save_tampered", http.StatusUnprocessableEntity},
+	{game.ErrUnknownSaveVersion, "unknown_save_version", http.StatusUnprocessableEntity},
+	{game.ErrBadCode, "bad_code", http.StatusBadRequest},
+	{game.ErrUnknownAbility, "unknown_ability", http.StatusUnprocessableEntity},
+	{game.ErrInvalidGame, "invalid_game", http.StatusUnprocessableEntity},
+	{game.ErrCorruptSave, "save_corrupt", http.StatusUnprocessableEntity}, // DecodeGame оборачивает ею все прочие ошибки
+}
+
+var internalError = apiError{code: "internal", status: http.StatusInternalServerError}
+
+func classifyError(err error) apiError {
+	for _, e := range apiErrors {
+		if errors.Is(err, e.err) {
+			return e
+		}
+	}
+	return internalError
+}
+
+// ErrorCode - код ошибки API; EnumValues перечисляет все коды для схемы ErrorResponse
+type ErrorCode string
+
+func (ErrorCode) EnumValues() []string {
+	codes := make([]string, 0, len(apiErrors)+1)
+	for _, e := range apiErrors {
+		codes = append(codes, e.code)
+	}
+	return append(codes, internalError.code)
+}
+
+// newErrorResponse переводит ошибку на язык клиента по ее коду. Текст самой ошибки
+// с подробностями уходит в detail без перевода
+func newErrorResponse(err error, lang i18n.Lang) (ErrorResponse, int) {
+	e := classifyError(err)
+	response := ErrorResponse{
+		Code:    ErrorCode(e.code),
+		Message: lang.Text("error." + e.code),
+		Detail:  err.Error(),
+	}
+
+	// список нарушений расстановки, чтобы клиент мог подсветить конкретные корабли
+	var fleetErr *game.FleetError
+	if errors.As(err, &fleetErr) {
+		response.Problems = make([]game.FleetProblem, len(fleetErr.Problems))
+		for i, problem := range fleetErr.Problems {
+			problem.Message = lang.Format(problem.Text)
+			response.Problems[i] = problem
+		}
+	}
+	return response, e.status
+}
+
+func sendError(w http.ResponseWriter, r *http.Request, err error) {
+	response, status := newErrorResponse(err, requestLang(r))
+	if status == http.StatusInternalServerError {
+		log.Printf("Внутренняя ошибка: %v", err)
+	}
+	sendJSON(w, response, status)
+}
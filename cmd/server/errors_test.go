@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sea_battle/game"
+	"sea_battle/i18n"
+	"testing"
+)
+
+func TestErrorCodesPreferSpecificSentinels(t *testing.T) {
+	g := newDefaultGame()
+	scanner, err := game.NewAbility("scanner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Player1.Abilities = append(g.Player1.Abilities, scanner)
+	data, err := game.EncodeGame(g, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, unknownAbility := game.DecodeGame(bytes.Replace(data, []byte(`"scanner"`), []byte(`"teleport"`), 1), nil)
+	_, badJSON := game.DecodeGame([]byte(`{"version": 2, "game": [}`), nil)
+
+	for _, tc := range []struct {
+		err  error
+		code ErrorCode
+	}{
+		{unknownAbility, "unknown_ability"},
+		{fmt.Errorf("%w: %w", game.ErrCorruptSave, game.ErrInvalidGame), "invalid_game"},
+		{badJSON, "save_corrupt"},
+		{&game.FleetError{}, "invalid_fleet"},
+	} {
+		if response, _ := newErrorResponse(tc.err, i18n.Default); response.Code != tc.code {
+			t.Errorf("%v: код %s, ожидался %s", tc.err, response.Code, tc.code)
+		}
+	}
+}
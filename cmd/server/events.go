@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sea_battle/game"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	eventBuffer       = 64
+	eventPingInterval = 15 * time.Second
+)
+
+// sseMessage - одно событие потока /api/events. ID имеет вид "<номер партии>:<номер события журнала>",
+// чтобы Last-Event-ID прежней партии сессии не совпал с событием новой
+type sseMessage struct {
+	ID   string
+	Name string
+	Data []byte
+}
+
+func eventID(generation, index int) string {
+	return fmt.Sprintf("%d:%d", generation, index)
+}
+
+// parseEventID разбирает ID события; ok = false, если ID не от этого сервера
+func parseEventID(id string) (generation, index int, ok bool) {
+	genText, indexText, found := strings.Cut(id, ":")
+	if !found {
+		return 0, 0, false
+	}
+	generation, err1 := strconv.Atoi(genText)
+	index, err2 := strconv.Atoi(indexText)
+	return generation, index, err1 == nil && err2 == nil
+}
+
+// gameResetMessage сообщает о смене партии; его номер события -1 - раньше любого события новой партии
+func gameResetMessage(generation int) sseMessage {
+	return sseMessage{ID: eventID(generation, -1), Name: "game_reset", Data: []byte("{}")}
+}
+
+// eventHub раздает события партии подписчикам потока. У хаба своя блокировка:
+// публикация идет из-под блокировки сессии и не должна ждать медленных клиентов
+type eventHub struct {
+	mu          sync.Mutex
+	subscribers map[chan sseMessage]struct{}
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subscribers: make(map[chan sseMessage]struct{})}
+}
+
+func (h *eventHub) subscribe() chan sseMessage {
+	ch := make(chan sseMessage, eventBuffer)
+	h.mu.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.mu.Unlock()
+	return ch
+}
+
+func (h *eventHub) unsubscribe(ch chan sseMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subscribers[ch]; ok {
+		delete(h.subscribers, ch)
+		close(ch)
+	}
+}
+
+// publish не блокируется: подписчик, который не успевает читать, отключается
+// и при переподключении дочитает пропущенное по Last-Event-ID
+func (h *eventHub) publish(msgs ...sseMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subscribers {
+		for _, msg := range msgs {
+			select {
+			case ch <- msg:
+			default:
+				delete(h.subscribers, ch)
+				close(ch)
+			}
+			if _, ok := h.subscribers[ch]; !ok {
+				break
+			}
+		}
+	}
+}
+
+// closeAll отключает всех подписчиков, например когда сессия истекла
+func (h *eventHub) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subscribers {
+		delete(h.subscribers, ch)
+		close(ch)
+	}
+}
+
+// sseMessages превращает событие журнала в события потока. Игрок сессии - всегда Player1,
+// поэтому выстрелы делятся на human_shot и bot_shot, а потопление дублируется в ship_sunk
+func sseMessages(generation int, g *game.Game, e game.Event) []sseMessage {
+	var names []string
+	switch e.Type {
+	case game.EventPlacement:
+		return nil
+	case game.EventAttack:
+		if e.Player == g.Player1.Name {
+			names = append(names, "human_shot")
+		} else {
+			names = append(names, "bot_shot")
+		}
+		if e.Attack != nil && e.Attack.Result == game.ResultSunk {
+			names = append(names, "ship_sunk")
+		}
+	default:
+		names = append(names, string(e.Type))
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil
+	}
+	msgs := make([]sseMessage, len(names))
+	for i, name := range names {
+		msgs[i] = sseMessage{ID: eventID(generation, e.Index), Name: name, Data: data}
+	}
+	return msgs
+}
+
+func writeSSE(w http.ResponseWriter, msg sseMessage) {
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", msg.ID, msg.Name, msg.Data)
+}
+
+// eventsHandler - поток Server-Sent Events партии сессии: выстрелы, потопления, способности,
+// передача хода и конец игры. При смене партии приходит game_reset, и клиент перечитывает /api/game
+func eventsHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		sendError(w, r, errStreamUnsupported)
+		return
+	}
+
+	s := sessionFromRequest(w, r)
+	if s == nil {
+		return
+	}
+	ch := s.events.subscribe()
+	defer s.events.unsubscribe(ch)
+
+	// после переподключения досылаем события, пропущенные с Last-Event-ID. Если за это время
+	// партия сменилась, клиенту хватит game_reset: он все равно перечитает /api/game
+	var missed []sseMessage
+	if generation, lastIndex, ok := parseEventID(r.Header.Get("Last-Event-ID")); ok {
+		s.mu.Lock()
+		if generation != s.generation {
+			missed = append(missed, gameResetMessage(s.generation))
+		} else {
+			for _, e := range s.Game.PublicHistory(s.Game.Player1) {
+				if e.Index > lastIndex {
+					missed = append(missed, sseMessages(s.generation, s.Game, e)...)
+				}
+			}
+		}
+		s.mu.Unlock()
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	for _, msg := range missed {
+		writeSSE(w, msg)
+	}
+	flusher.Flush()
+
+	ping := time.NewTicker(eventPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			writeSSE(w, msg)
+			flusher.Flush()
+		case <-ping.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// firstEvent переподключается к /events с lastEventID и возвращает ID и имя первого события потока
+func (c *specClient) firstEvent(lastEventID string) (id, name string) {
+	c.t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.server.URL+"/api/events", nil)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	req.Header.Set("Last-Event-ID", lastEventID)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() && (id == "" || name == "") {
+		if value, ok := strings.CutPrefix(scanner.Text(), "id: "); ok {
+			id = value
+		}
+		if value, ok := strings.CutPrefix(scanner.Text(), "event: "); ok {
+			name = value
+		}
+	}
+	return id, name
+}
+
+func TestEventsResumeAcrossGames(t *testing.T) {
+	c := newSpecClient(t)
+	c.call(http.MethodPost, "/games", nil, nil, http.StatusOK)
+	c.call(http.MethodPost, "/attack", url.Values{"x": {"0"}, "y": {"0"}}, nil, http.StatusOK)
+	c.call(http.MethodPost, "/newgame/auto", url.Values{"seed": {"1"}}, nil, http.StatusOK)
+
+	// ID из прежней партии не должен пропустить события новой
+	if id, name := c.firstEvent("1:5"); id != "2:-1" || name != "game_reset" {
+		t.Errorf("после смены партии пришло %s %s, ожидался game_reset 2:-1", id, name)
+	}
+
+	c.call(http.MethodPost, "/attack", url.Values{"x": {"0"}, "y": {"0"}}, nil, http.StatusOK)
+	if id, name := c.firstEvent("2:-1"); !strings.HasPrefix(id, "2:") || name != "human_shot" {
+		t.Errorf("в той же партии пришло %s %s, ожидался human_shot 2:<номер>", id, name)
+	}
+}
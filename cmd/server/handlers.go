@@ -3,293 +3,476 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"sea_battle/game"
-	"strconv"
-	"time"
+	"sea_battle/i18n"
 )
 
-type ShipPlacementPayload struct {
-	Ships []game.Ship `json:"ships"`
-}
-
 func sendJSON(w http.ResponseWriter, data interface{}, status int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(data)
 }
 
-func sendJSONError(w http.ResponseWriter, message string, status int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(map[string]string{"Message": message})
+func gamesHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		sendJSON(w, GamesResponse{Games: sessions.List()}, http.StatusOK)
+	case http.MethodPost:
+		s, err := sessions.Create(newDefaultGame())
+		if err != nil {
+			sendError(w, r, err)
+			return
+		}
+		attachSession(w, s)
+		sendJSON(w, CreateGameResponse{Message: requestLang(r).Text("server.game_created"), GameID: s.ID}, http.StatusOK)
+	}
 }
 
 func gameStatusHandler(w http.ResponseWriter, r *http.Request) {
-	gameMutex.Lock()
-	defer gameMutex.Unlock()
-	sendJSON(w, map[string]interface{}{"game": currentGame, "save_exists": saveExists}, http.StatusOK)
+	s := sessionFromRequest(w, r)
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	view := game.NewGameView(s.Game, s.Game.Player1, requestLang(r))
+	sendJSON(w, GameStatusResponse{Game: view, GameID: s.ID, SaveExists: saves.HasSaves(saveOwnerFromRequest(w, r))}, http.StatusOK)
 }
 
 func saveGameHandler(w http.ResponseWriter, r *http.Request) {
-	gameMutex.Lock()
-	defer gameMutex.Unlock()
+	s := sessionFromRequest(w, r)
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	if r.Method != http.MethodPost {
-		sendJSONError(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+	var req SlotRequest
+	if err := decodeQuery(r, &req); err != nil {
+		sendError(w, r, err)
+		return
+	}
+	slot, err := normalizeSlot(req.Slot)
+	if err != nil {
+		sendError(w, r, err)
 		return
 	}
 
-	err := currentGame.SaveGame(saveFilename)
+	err = saves.Save(saveOwnerFromRequest(w, r), slot, s.Game)
 	if err != nil {
-		sendJSONError(w, "Не удалось сохранить игру: "+err.Error(), http.StatusInternalServerError)
+		sendError(w, r, fmt.Errorf("Не удалось сохранить игру: %w", err))
 		return
 	}
 
-	sendJSON(w, map[string]string{"message": "Игра успешно сохранена"}, http.StatusOK)
+	sendJSON(w, SlotResponse{Message: requestLang(r).Text("server.game_saved"), Slot: slot}, http.StatusOK)
 }
 
 func loadGameHandler(w http.ResponseWriter, r *http.Request) {
-	gameMutex.Lock()
-	defer gameMutex.Unlock()
-
-	if r.Method != http.MethodPost {
-		sendJSONError(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+	var req LoadRequest
+	if err := decodeQuery(r, &req); err != nil {
+		sendError(w, r, err)
 		return
 	}
-
-	if !saveExists {
-		sendJSONError(w, "Файл сохранения не найден", http.StatusNotFound)
+	slot, err := normalizeSlot(req.Slot)
+	if err != nil {
+		sendError(w, r, err)
 		return
 	}
 
-	loadedGame, err := game.LoadGame(saveFilename)
+	owner := saveOwnerFromRequest(w, r)
+	if req.Shared {
+		owner = sharedOwner
+	}
+	loadedGame, err := saves.Load(owner, slot)
 	if err != nil {
-		sendJSONError(w, "Не удалось загрузить игру: "+err.Error(), http.StatusInternalServerError)
+		sendError(w, r, fmt.Errorf("Не удалось загрузить игру %s: %w", slot, err))
 		return
 	}
 
-	currentGame = loadedGame
-	sendJSON(w, map[string]string{"message": "Игра успешно загружена"}, http.StatusOK)
+	if startGame(w, r, loadedGame) == nil {
+		return
+	}
+	sendJSON(w, SlotResponse{Message: requestLang(r).Text("server.game_loaded"), Slot: slot}, http.StatusOK)
+}
+
+// savesHandler - GET отдает свои и общие слоты клиента, DELETE удаляет его слот из ?slot=.
+// Чужие слоты не видны, а общие удалить нельзя
+func savesHandler(w http.ResponseWriter, r *http.Request) {
+	owner := saveOwnerFromRequest(w, r)
+	switch r.Method {
+	case http.MethodGet:
+		infos, err := saves.List(owner)
+		if err != nil {
+			sendError(w, r, fmt.Errorf("Не удалось прочитать сохранения: %w", err))
+			return
+		}
+		sendJSON(w, SavesResponse{Saves: infos}, http.StatusOK)
+	case http.MethodDelete:
+		var req SlotRequest
+		if err := decodeQuery(r, &req); err != nil {
+			sendError(w, r, err)
+			return
+		}
+		slot, err := normalizeSlot(req.Slot)
+		if err != nil {
+			sendError(w, r, err)
+			return
+		}
+
+		err = saves.Delete(owner, slot)
+		if err != nil {
+			sendError(w, r, fmt.Errorf("Не удалось удалить сохранение %s: %w", slot, err))
+			return
+		}
+		sendJSON(w, SlotResponse{Message: requestLang(r).Text("server.save_deleted"), Slot: slot}, http.StatusOK)
+	}
+}
+
+// findAbility ищет способность игрока по ID или по названию на языке запроса, -1 - если такой нет
+func findAbility(p *game.Player, name string, lang i18n.Lang) int {
+	info, ok := game.FindAbility(name, lang)
+	if !ok {
+		return -1
+	}
+	for i, ab := range p.Abilities {
+		if ab.ID() == info.ID {
+			return i
+		}
+	}
+	return -1
+}
+
+// abilitiesHandler отдает все способности из реестра с названиями на языке запроса
+func abilitiesHandler(w http.ResponseWriter, r *http.Request) {
+	lang := requestLang(r)
+	response := AbilitiesResponse{Abilities: []game.AbilityDTO{}}
+	for _, info := range game.Abilities() {
+		response.Abilities = append(response.Abilities, info.DTO(lang))
+	}
+	sendJSON(w, response, http.StatusOK)
+}
+
+func gameOverMessage(winner *game.Player) i18n.Message {
+	return i18n.Message{ID: "game.over", Params: i18n.Params{"winner": winner.Name}}
+}
+
+// newGameOptions разбирает параметры новой партии из строки запроса
+func newGameOptions(r *http.Request) (game.Options, error) {
+	var req NewGameRequest
+	if err := decodeQuery(r, &req); err != nil {
+		return game.Options{}, err
+	}
+	return req.Options()
 }
 
 func newGameAutoHandler(w http.ResponseWriter, r *http.Request) {
-	gameMutex.Lock()
-	defer gameMutex.Unlock()
-	if r.Method != http.MethodPost {
-		sendJSONError(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+	opts, err := newGameOptions(r)
+	if err != nil {
+		sendError(w, r, err)
 		return
 	}
-	currentGame = game.NewGame()
-	sendJSON(w, map[string]string{"message": "Новая игра успешно создана"}, http.StatusOK)
+
+	newGame, err := game.NewGame(opts)
+	if err != nil {
+		sendError(w, r, fmt.Errorf("Не удалось создать игру: %w", err))
+		return
+	}
+
+	if startGame(w, r, newGame) == nil {
+		return
+	}
+	sendJSON(w, MessageResponse{Message: requestLang(r).Text("server.game_created")}, http.StatusOK)
 }
 
 func newGameManualHandler(w http.ResponseWriter, r *http.Request) {
-	gameMutex.Lock()
-	defer gameMutex.Unlock()
-
-	if r.Method != http.MethodPost {
-		sendJSONError(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+	opts, err := newGameOptions(r)
+	if err != nil {
+		sendError(w, r, err)
 		return
 	}
 
 	var payload ShipPlacementPayload
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		sendJSONError(w, "Неверные данные для расстановки кораблей: "+err.Error(), http.StatusBadRequest)
+		sendError(w, r, fmt.Errorf("%w: неверные данные для расстановки кораблей: %v", errBadParam, err))
+		return
+	}
+
+	playerBoard, err := game.NewBoardWithShips(opts.Rules, payload.Ships)
+	if err != nil {
+		sendError(w, r, fmt.Errorf("Ошибка при расстановке кораблей: %w", err))
 		return
 	}
 
-	playerBoard, err := game.NewBoardWithShips(payload.Ships)
+	newGame, err := game.NewGameManual(opts, playerBoard)
 	if err != nil {
-		sendJSONError(w, "Ошибка при расстановке кораблей: "+err.Error(), http.StatusBadRequest)
+		sendError(w, r, fmt.Errorf("Не удалось создать игру: %w", err))
 		return
 	}
 
-	currentGame = game.NewGameManual(playerBoard)
-	sendJSON(w, map[string]string{"message": "Новая игра (ручная расстановка) успешно создана"}, http.StatusOK)
+	if startGame(w, r, newGame) == nil {
+		return
+	}
+	sendJSON(w, MessageResponse{Message: requestLang(r).Text("server.game_created_manual")}, http.StatusOK)
 }
 
 func abilityHandler(w http.ResponseWriter, r *http.Request) {
-	gameMutex.Lock()
-	defer gameMutex.Unlock()
-
-	if r.Method != http.MethodPost {
-		sendJSONError(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+	s := sessionFromRequest(w, r)
+	if s == nil {
 		return
 	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	if currentGame.CurrentPlayer.Name != "Player" {
-		sendJSONError(w, "Сейчас не ваш ход", http.StatusForbidden)
+	if err := s.Game.CheckTurn(s.Game.Player1); err != nil {
+		sendError(w, r, err)
 		return
 	}
 
-	query := r.URL.Query()
-	abilityName := query.Get("ability_name")
-	if abilityName == "" {
-		sendJSONError(w, "параметр 'ability_name' обязателен", http.StatusBadRequest)
+	var req AbilityRequest
+	if err := decodeQuery(r, &req); err != nil {
+		sendError(w, r, err)
 		return
 	}
 
-	player := currentGame.Player1
-	var selectedAbility game.Ability
-	abilityIndex := -1
+	lang := requestLang(r)
+	player := s.Game.Player1
+	abilityIndex := findAbility(player, req.AbilityName, lang)
+	if abilityIndex == -1 {
+		sendError(w, r, game.ErrNoSuchAbility)
+		return
+	}
 
-	for i, ab := range player.Abilities {
-		if ab.Name() == abilityName {
-			selectedAbility = ab
-			abilityIndex = i
-			break
+	var target *game.AbilityTarget
+	info := game.AbilityInfoOf(player.Abilities[abilityIndex])
+	if info.RequiresTarget {
+		if req.X == nil || req.Y == nil {
+			sendError(w, r, fmt.Errorf("%w: не заданы координаты x и y", game.ErrNeedTarget))
+			return
 		}
+		target = &game.AbilityTarget{Point: game.Point{X: *req.X, Y: *req.Y}}
+	}
+	if info.RequiresDirection {
+		if req.Direction == "" {
+			sendError(w, r, fmt.Errorf("%w: не задано направление direction", game.ErrNeedTarget))
+			return
+		}
+		target.Direction = game.Direction(req.Direction)
 	}
 
-	if selectedAbility == nil {
-		sendJSONError(w, "у вас нет такой способности или она не существует", http.StatusNotFound)
+	result, err := s.Game.UseAbility(player, abilityIndex, target)
+	if err != nil {
+		sendError(w, r, fmt.Errorf("ошибка применения способности: %w", err))
 		return
 	}
 
-	var target *game.Point
-	if selectedAbility.RequiresTarget() {
-		x, y, err := HandlerCoords(w, r)
-		if err != nil {
-			sendJSONError(w, err.Error(), http.StatusBadRequest)
-			return
-		}
-		target = &game.Point{X: x, Y: y}
+	response := newAbilityResponse(result, lang)
+	if winner, over := s.Game.CheckGameOver(); over {
+		response.GameOver = true
+		response.Winner = winner.Name
 	}
+	sendJSON(w, response, http.StatusOK)
+}
+
+func rulesHandler(w http.ResponseWriter, r *http.Request) {
+	sendJSON(w, RulesResponse{Presets: game.RulePresets, Default: game.DefaultRules()}, http.StatusOK)
+}
 
-	result, err := selectedAbility.Apply(currentGame, target)
+func newDefaultGame() *game.Game {
+	g, err := game.NewGame(game.Options{Rules: game.DefaultRules(), Difficulty: game.DefaultDifficulty})
 	if err != nil {
-		sendJSONError(w, "ошибка применения способности: "+err.Error(), http.StatusInternalServerError)
+		panic("не удалось создать игру по классическим правилам: " + err.Error())
+	}
+	return g
+}
+
+// historyHandler отдает журнал партии, а с параметром at - состояние партии после первых at событий
+func historyHandler(w http.ResponseWriter, r *http.Request) {
+	s := sessionFromRequest(w, r)
+	if s == nil {
 		return
 	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	player.Abilities = append(player.Abilities[:abilityIndex], player.Abilities[abilityIndex+1:]...)
+	var req HistoryRequest
+	if err := decodeQuery(r, &req); err != nil {
+		sendError(w, r, err)
+		return
+	}
+	if req.At == nil {
+		sendJSON(w, HistoryResponse{
+			Events: s.Game.PublicHistory(s.Game.Player1),
+			Total:  len(s.Game.History),
+		}, http.StatusOK)
+		return
+	}
 
-	if currentGame.Player2.MyBoard.AllShipSunk() {
-		response := handlerGameOver(result.Message, currentGame.Player1)
-		sendJSON(w, response, http.StatusOK)
-		currentGame = game.NewGame()
+	replay, err := s.Game.ReplayTo(*req.At)
+	if err != nil {
+		sendError(w, r, err)
 		return
 	}
 
-	sendJSON(w, result, http.StatusOK)
+	sendJSON(w, HistoryResponse{
+		At:    req.At,
+		Total: len(s.Game.History),
+		Game:  game.NewGameView(replay, replay.Player1, requestLang(r)),
+	}, http.StatusOK)
 }
 
-func HandlerCoords(w http.ResponseWriter, r *http.Request) (int, int, error) {
-	query := r.URL.Query()
-	xStr := query.Get("x")
-	yStr := query.Get("y")
+// exportHandler отдает позицию партии короткой строкой для баг-репортов. Код всей партии
+// раскрывает флот бота, поэтому пока игра идет, отдается только поле игрока, если сервер
+// не запущен с -debug
+func exportHandler(w http.ResponseWriter, r *http.Request) {
+	s := sessionFromRequest(w, r)
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	board, err := game.EncodeBoardCode(s.Game.Player1.MyBoard)
+	if err != nil {
+		sendError(w, r, fmt.Errorf("Не удалось закодировать поле: %w", err))
+		return
+	}
+	resp := ExportResponse{Board: board}
 
-	if xStr == "" || yStr == "" {
-		return 0, 0, fmt.Errorf("не заданы координаты x и y")
+	if s.Game.IsOver() || debugMode {
+		resp.Code, err = game.EncodeGameCode(s.Game)
+		if err != nil {
+			sendError(w, r, fmt.Errorf("Не удалось закодировать партию: %w", err))
+			return
+		}
 	}
 
-	x, errX := strconv.Atoi(xStr)
-	if errX != nil {
-		return 0, 0, fmt.Errorf("координата x должна быть числом")
+	sendJSON(w, resp, http.StatusOK)
+}
+
+// importHandler заменяет партию сессии позицией из кода, полученного от exportHandler
+func importHandler(w http.ResponseWriter, r *http.Request) {
+	var req ImportRequest
+	if err := decodeQuery(r, &req); err != nil {
+		sendError(w, r, err)
+		return
 	}
 
-	y, errY := strconv.Atoi(yStr)
-	if errY != nil {
-		return 0, 0, fmt.Errorf("координата y должна быть числом")
+	imported, err := game.DecodeGameCode(req.Code)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+	if imported.Player2.Strategy == nil {
+		sendError(w, r, errNotAgainstBot)
+		return
 	}
 
-	return x, y, nil
+	if startGame(w, r, imported) == nil {
+		return
+	}
+	sendJSON(w, MessageResponse{Message: requestLang(r).Text("server.game_imported")}, http.StatusOK)
 }
 
 func attackHandler(w http.ResponseWriter, r *http.Request) {
-	gameMutex.Lock()
-	defer gameMutex.Unlock()
-
-	if r.Method != http.MethodPost {
-		sendJSONError(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+	s := sessionFromRequest(w, r)
+	if s == nil {
 		return
 	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	if currentGame.CurrentPlayer.Name != "Player" {
-		sendJSONError(w, "Сейчас не ваш ход", http.StatusForbidden)
+	if err := s.Game.CheckTurn(s.Game.Player1); err != nil {
+		sendError(w, r, err)
 		return
 	}
 
-	x, y, err := HandlerCoords(w, r)
-	if err != nil {
-		sendJSONError(w, err.Error(), http.StatusBadRequest)
+	var req CoordsRequest
+	if err := decodeQuery(r, &req); err != nil {
+		sendError(w, r, err)
 		return
 	}
 
-	result, markedPoints, msg, err := currentGame.HandleHumanTurn(x, y)
+	attack, msg, err := s.Game.HandleHumanTurn(req.X, req.Y)
 	if err != nil {
-		sendJSONError(w, err.Error(), http.StatusBadRequest)
+		sendError(w, r, err)
 		return
 	}
 
-	if currentGame.Player2.MyBoard.AllShipSunk() {
-		response := handlerGameOver("Вы победили!", currentGame.Player1)
+	lang := requestLang(r)
+	response := AttackResponse{Message: lang.Format(msg), HumanMoveResult: newMoveResult(attack)}
+	if winner, over := s.Game.CheckGameOver(); over {
+		response.Message = lang.Format(gameOverMessage(winner))
+		response.GameOver = true
+		response.Winner = winner.Name
 		sendJSON(w, response, http.StatusOK)
-		currentGame = game.NewGame()
-		return
-	}
-
-	var computerMoves []map[string]interface{}
-	if result == game.ResultMiss {
-		currentGame.SwitchPlayer()
-		for {
-			time.Sleep(300 * time.Millisecond)
-
-			compTarget, result, newlyMarked, err := currentGame.HandleComputerTurn()
-			if err != nil {
-				sendJSONError(w, "Ошибка в ходе бота: "+err.Error(), http.StatusInternalServerError)
-				return
-			}
-
-			computerMoves = append(computerMoves, map[string]interface{}{
-				"x":             compTarget.X,
-				"y":             compTarget.Y,
-				"result":        result,
-				"marked_points": newlyMarked,
-			})
-			log.Printf("Ход компьютера: %+v, Результат: %v", compTarget, result)
-
-			if currentGame.Player1.MyBoard.AllShipSunk() {
-				response := handlerGameOver("Вы победили!", currentGame.Player2)
-				sendJSON(w, response, http.StatusOK)
-				currentGame = game.NewGame()
-				return
-			}
-
-			if result == game.ResultMiss {
-				msg = "Бот промахнулся. Теперь ваш ход"
-				currentGame.SwitchPlayer()
-				break
-			}
-
-			log.Printf("Ход компьютера: %v", result)
-		}
+		return
+	}
 
+	// ход бота выполняет воркер сессии; его выстрелы приходят в /api/events или видны в /api/game
+	if !attack.Result.KeepsTurn() {
+		s.Game.SwitchPlayer()
+		s.startBotTurn()
+		response.BotTurn = true
 	}
+	sendJSON(w, response, http.StatusOK)
+}
 
-	response := map[string]interface{}{
-		"message":        msg,
-		"game_over":      false,
-		"winner":         "",
-		"computer_moves": computerMoves,
-		"human_move_result": map[string]interface{}{
-			"x":             x,
-			"y":             y,
-			"result":        result,
-			"marked_points": markedPoints,
-		},
+// salvoHandler - залп игрока в режиме salvo. После залпа ход всегда переходит к боту
+func salvoHandler(w http.ResponseWriter, r *http.Request) {
+	s := sessionFromRequest(w, r)
+	if s == nil {
+		return
 	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.Game.CheckTurn(s.Game.Player1); err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	var req SalvoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, r, fmt.Errorf("%w: неверное тело залпа: %v", errBadParam, err))
+		return
+	}
+
+	attacks, err := s.Game.HandleSalvo(req.Shots)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	lang := requestLang(r)
+	response := SalvoResponse{Message: lang.Format(salvoMessage(attacks)), Shots: make([]MoveResult, 0, len(attacks))}
+	for _, attack := range attacks {
+		response.Shots = append(response.Shots, newMoveResult(attack))
+	}
+	if winner, over := s.Game.CheckGameOver(); over {
+		response.Message = lang.Format(gameOverMessage(winner))
+		response.GameOver = true
+		response.Winner = winner.Name
+		sendJSON(w, response, http.StatusOK)
+		return
+	}
+
+	s.Game.SwitchPlayer()
+	s.startBotTurn()
+	response.BotTurn = true
 	sendJSON(w, response, http.StatusOK)
 }
 
-func handlerGameOver(abilityResultMessage string, winner *game.Player) map[string]interface{} {
-	msg := fmt.Sprintf("Игра окончена! Победитель: %s", winner.Name)
-	return map[string]interface{}{
-		"message":        msg,
-		"ability_result": abilityResultMessage,
-		"game_over":      true,
-		"winner":         winner.Name,
+func salvoMessage(attacks []*game.AttackResultData) i18n.Message {
+	hits, sunk := 0, 0
+	for _, attack := range attacks {
+		switch attack.Result {
+		case game.ResultSunk:
+			sunk++
+			hits++
+		case game.ResultHit:
+			hits++
+		}
 	}
+	return i18n.Message{ID: "salvo.done", Params: i18n.Params{"hits": hits, "sunk": sunk}}
 }
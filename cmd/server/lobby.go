@@ -0,0 +1,588 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sea_battle/game"
+	"sea_battle/i18n"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	phaseWaiting  = "waiting" // ждем второго игрока
+	phasePlacing  = "placing" // игроки расставляют корабли
+	phasePlaying  = "playing"
+	phaseFinished = "finished"
+)
+
+type Seat struct {
+	Name   string
+	Token  string
+	Board  *game.Board
+	client *wsClient
+}
+
+// Match - партия двух людей, ходы которой рассылаются обоим по WebSocket
+type Match struct {
+	ID        string
+	CreatedAt time.Time
+	LastSeen  time.Time
+	Seats     [2]*Seat
+	Options   game.Options
+	Game      *game.Game
+	Winner    string
+
+	mu sync.Mutex
+}
+
+type MatchInfo struct {
+	ID        string     `json:"id"`
+	Phase     string     `json:"phase"`
+	Rules     game.Rules `json:"rules"`
+	Players   []string   `json:"players"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type Lobby struct {
+	mu      sync.RWMutex
+	matches map[string]*Match
+	ttl     time.Duration
+}
+
+type wsClient struct {
+	conn *websocket.Conn
+	send chan wsOutgoing
+	lang i18n.Lang // язык сообщений, выбранный при подключении
+}
+
+type wsIncoming struct {
+	Type        string         `json:"type"`
+	Ships       []game.Ship    `json:"ships,omitempty"`
+	X           int            `json:"x"`
+	Y           int            `json:"y"`
+	AbilityName string         `json:"ability_name,omitempty"`
+	Shots       []game.Point   `json:"shots,omitempty"` // цели залпа для сообщения salvo
+	Direction   game.Direction `json:"direction,omitempty"`
+}
+
+type wsOutgoing struct {
+	Type     string              `json:"type"`
+	Phase    string              `json:"phase,omitempty"`
+	Seat     int                 `json:"seat"`
+	Game     *game.GameView      `json:"game,omitempty"`
+	Move     *wsMove             `json:"move,omitempty"`
+	Ability  *AbilityResponse    `json:"ability,omitempty"`
+	Winner   string              `json:"winner,omitempty"`
+	Message  string              `json:"message,omitempty"`
+	Text     *i18n.Message       `json:"-"`              // переводится в Message на язык каждого получателя
+	Code     ErrorCode           `json:"code,omitempty"` // для сообщений error, как в ErrorResponse
+	Problems []game.FleetProblem `json:"problems,omitempty"`
+}
+
+type wsMove struct {
+	Player       string            `json:"player"`
+	X            int               `json:"x"`
+	Y            int               `json:"y"`
+	Result       game.AttackResult `json:"result"`
+	MarkedPoints []game.Point      `json:"marked_points,omitempty"`
+	BonusHit     *game.Point       `json:"bonus_hit,omitempty"`
+}
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+func NewLobby(ttl time.Duration) *Lobby {
+	return &Lobby{
+		matches: make(map[string]*Match),
+		ttl:     ttl,
+	}
+}
+
+// Create открывает партию; без имени создатель получает имя по умолчанию на своем языке lang
+func (l *Lobby) Create(name string, lang i18n.Lang, opts game.Options) (*Match, *Seat) {
+	if name == "" {
+		name = lang.Text("lobby.seat1")
+	}
+
+	now := time.Now()
+	seat := &Seat{Name: name, Token: newSessionID()}
+	m := &Match{
+		ID:        newSessionID(),
+		CreatedAt: now,
+		LastSeen:  now,
+		Seats:     [2]*Seat{seat, nil},
+		Options:   opts,
+	}
+
+	l.mu.Lock()
+	l.matches[m.ID] = m
+	l.mu.Unlock()
+	return m, seat
+}
+
+func (l *Lobby) Get(id string) (*Match, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	m, ok := l.matches[id]
+	return m, ok
+}
+
+func (l *Lobby) List() []MatchInfo {
+	l.mu.RLock()
+	matches := make([]*Match, 0, len(l.matches))
+	for _, m := range l.matches {
+		matches = append(matches, m)
+	}
+	l.mu.RUnlock()
+
+	infos := make([]MatchInfo, 0, len(matches))
+	for _, m := range matches {
+		m.mu.Lock()
+		info := MatchInfo{ID: m.ID, Phase: m.phase(), Rules: m.Options.Rules, CreatedAt: m.CreatedAt}
+		for _, seat := range m.Seats {
+			if seat != nil {
+				info.Players = append(info.Players, seat.Name)
+			}
+		}
+		m.mu.Unlock()
+		infos = append(infos, info)
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].CreatedAt.Before(infos[j].CreatedAt)
+	})
+	return infos
+}
+
+func (l *Lobby) Expire() int {
+	deadline := time.Now().Add(-l.ttl)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	removed := 0
+	for id, m := range l.matches {
+		m.mu.Lock()
+		expired := m.LastSeen.Before(deadline)
+		if expired {
+			for _, seat := range m.Seats {
+				if seat != nil && seat.client != nil {
+					close(seat.client.send)
+					seat.client = nil
+				}
+			}
+		}
+		m.mu.Unlock()
+		if expired {
+			delete(l.matches, id)
+			removed++
+		}
+	}
+	return removed
+}
+
+func (l *Lobby) RunJanitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		l.Expire()
+	}
+}
+
+// Join занимает второе место в партии
+func (m *Match) Join(name string, lang i18n.Lang) (*Seat, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Seats[1] != nil {
+		return nil, false
+	}
+
+	if name == "" {
+		name = lang.Text("lobby.seat2")
+	}
+	if name == m.Seats[0].Name {
+		name += " (2)"
+	}
+
+	seat := &Seat{Name: name, Token: newSessionID()}
+	m.Seats[1] = seat
+	m.LastSeen = time.Now()
+	m.broadcastState()
+	return seat, true
+}
+
+func (m *Match) phase() string {
+	switch {
+	case m.Seats[1] == nil:
+		return phaseWaiting
+	case m.Game == nil:
+		return phasePlacing
+	case m.Winner != "":
+		return phaseFinished
+	default:
+		return phasePlaying
+	}
+}
+
+func (m *Match) seatIndex(token string) int {
+	for i, seat := range m.Seats {
+		if seat != nil && seat.Token == token {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *Match) seatPlayer(seat int) *game.Player {
+	if seat == 0 {
+		return m.Game.Player1
+	}
+	return m.Game.Player2
+}
+
+// sendError отправляет игроку ошибку с тем же кодом, что вернул бы HTTP API
+func (m *Match) sendError(seat int, err error) {
+	response, _ := newErrorResponse(err, m.seatLang(seat))
+	m.sendTo(seat, wsOutgoing{Type: "error", Code: response.Code, Message: response.Message, Problems: response.Problems})
+}
+
+func (m *Match) seatLang(seat int) i18n.Lang {
+	if s := m.Seats[seat]; s != nil && s.client != nil {
+		return s.client.lang
+	}
+	return i18n.Default
+}
+
+func (m *Match) sendTo(seat int, msg wsOutgoing) {
+	s := m.Seats[seat]
+	if s == nil || s.client == nil {
+		return
+	}
+
+	msg.Seat = seat
+	if msg.Text != nil {
+		msg.Message = s.client.lang.Format(*msg.Text)
+	}
+	select {
+	case s.client.send <- msg:
+	default:
+		log.Printf("Партия %s: игрок %s не успевает читать сообщения, соединение закрыто", m.ID, s.Name)
+		close(s.client.send)
+		s.client = nil
+	}
+}
+
+func (m *Match) broadcast(msg wsOutgoing) {
+	for i := range m.Seats {
+		m.sendTo(i, msg)
+	}
+}
+
+func (m *Match) broadcastState() {
+	for i := range m.Seats {
+		msg := wsOutgoing{Type: "state", Phase: m.phase(), Winner: m.Winner}
+		if m.Game != nil {
+			msg.Game = game.NewGameView(m.Game, m.seatPlayer(i), m.seatLang(i))
+		}
+		m.sendTo(i, msg)
+	}
+}
+
+func (m *Match) handleMessage(seat int, in wsIncoming) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.LastSeen = time.Now()
+
+	switch in.Type {
+	case "place":
+		m.handlePlace(seat, in)
+	case "attack":
+		m.handleAttack(seat, in)
+	case "salvo":
+		m.handleSalvo(seat, in)
+	case "ability":
+		m.handleAbility(seat, in)
+	default:
+		m.sendError(seat, fmt.Errorf("%w: %s", errUnknownWSMessage, in.Type))
+	}
+}
+
+func (m *Match) handlePlace(seat int, in wsIncoming) {
+	if m.Game != nil {
+		m.sendError(seat, errGameStarted)
+		return
+	}
+
+	board, err := game.NewBoardWithShips(m.Options.Rules, in.Ships)
+	if err != nil {
+		m.sendError(seat, fmt.Errorf("Ошибка при расстановке кораблей: %w", err))
+		return
+	}
+	m.Seats[seat].Board = board
+
+	if m.Seats[0].Board != nil && m.Seats[1] != nil && m.Seats[1].Board != nil {
+		m.Game = game.NewGameVersus(m.Options, m.Seats[0].Name, m.Seats[0].Board, m.Seats[1].Name, m.Seats[1].Board)
+	}
+	m.broadcastState()
+}
+
+func (m *Match) checkTurn(seat int) bool {
+	if m.Game == nil {
+		m.sendError(seat, errGameNotStarted)
+		return false
+	}
+	if err := m.Game.CheckTurn(m.seatPlayer(seat)); err != nil {
+		m.sendError(seat, err)
+		return false
+	}
+	return true
+}
+
+func (m *Match) handleAttack(seat int, in wsIncoming) {
+	if !m.checkTurn(seat) {
+		return
+	}
+
+	player := m.seatPlayer(seat)
+	attack, msg, err := m.Game.HandleHumanTurn(in.X, in.Y)
+	if err != nil {
+		m.sendError(seat, err)
+		return
+	}
+
+	// сообщение стрелка написано от его лица, сопернику нужно свое
+	opponentMsg := opponentMessage(attack)
+	for i := range m.Seats {
+		text := &msg
+		if i != seat {
+			text = &opponentMsg
+		}
+		m.sendTo(i, wsOutgoing{Type: "move", Text: text, Move: newWSMove(player, attack)})
+	}
+
+	if m.finishIfOver() {
+		return
+	}
+	if !attack.Result.KeepsTurn() {
+		m.Game.SwitchPlayer()
+	}
+	m.broadcastState()
+}
+
+// opponentMessage - итог выстрела глазами того, по чьему полю стреляли
+func opponentMessage(attack *game.AttackResultData) i18n.Message {
+	switch attack.Result {
+	case game.ResultMiss:
+		return i18n.M("opponent.miss")
+	case game.ResultBlocked:
+		return i18n.M("opponent.blocked")
+	case game.ResultSunk:
+		return i18n.M("opponent.sunk")
+	default:
+		return i18n.M("opponent.hit")
+	}
+}
+
+// handleSalvo рассылает выстрелы залпа по одному; итог залпа приходит с последним из них
+func (m *Match) handleSalvo(seat int, in wsIncoming) {
+	if !m.checkTurn(seat) {
+		return
+	}
+
+	player := m.seatPlayer(seat)
+	attacks, err := m.Game.HandleSalvo(in.Shots)
+	if err != nil {
+		m.sendError(seat, err)
+		return
+	}
+
+	text := salvoMessage(attacks)
+	for i, attack := range attacks {
+		msg := wsOutgoing{Type: "move", Move: newWSMove(player, attack)}
+		if i == len(attacks)-1 {
+			msg.Text = &text
+		}
+		m.broadcast(msg)
+	}
+
+	if m.finishIfOver() {
+		return
+	}
+	m.Game.SwitchPlayer()
+	m.broadcastState()
+}
+
+func (m *Match) handleAbility(seat int, in wsIncoming) {
+	if !m.checkTurn(seat) {
+		return
+	}
+
+	player := m.seatPlayer(seat)
+	abilityIndex := findAbility(player, in.AbilityName, m.seatLang(seat))
+	if abilityIndex == -1 {
+		m.sendError(seat, game.ErrNoSuchAbility)
+		return
+	}
+
+	ability := player.Abilities[abilityIndex]
+	var target *game.AbilityTarget
+	if game.AbilityInfoOf(ability).RequiresTarget {
+		target = &game.AbilityTarget{Point: game.Point{X: in.X, Y: in.Y}, Direction: in.Direction}
+	}
+
+	result, err := m.Game.UseAbility(player, abilityIndex, target)
+	if err != nil {
+		m.sendError(seat, fmt.Errorf("ошибка применения способности: %w", err))
+		return
+	}
+
+	// результат сканера видит только тот, кто его применил
+	response := newAbilityResponse(result, m.seatLang(seat))
+	m.sendTo(seat, wsOutgoing{Type: "ability", Ability: &response, Message: response.Message})
+	for _, attack := range result.AttackResults {
+		m.broadcast(wsOutgoing{Type: "move", Move: newWSMove(player, attack)})
+	}
+
+	if m.finishIfOver() {
+		return
+	}
+	m.broadcastState()
+}
+
+func newWSMove(player *game.Player, attack *game.AttackResultData) *wsMove {
+	return &wsMove{
+		Player:       player.Name,
+		X:            attack.Target.X,
+		Y:            attack.Target.Y,
+		Result:       attack.Result,
+		MarkedPoints: attack.MarkedPoints,
+		BonusHit:     attack.BonusHit,
+	}
+}
+
+func (m *Match) finishIfOver() bool {
+	winner, over := m.Game.CheckGameOver()
+	if !over {
+		return false
+	}
+
+	m.Winner = winner.Name
+	text := gameOverMessage(winner)
+	m.broadcast(wsOutgoing{Type: "game_over", Winner: winner.Name, Text: &text})
+	m.broadcastState()
+	return true
+}
+
+func (c *wsClient) writeLoop() {
+	for msg := range c.send {
+		if err := c.conn.WriteJSON(msg); err != nil {
+			break
+		}
+	}
+	c.conn.Close()
+}
+
+func lobbyHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		sendJSON(w, LobbyResponse{Matches: lobby.List()}, http.StatusOK)
+	case http.MethodPost:
+		var req LobbyCreateRequest
+		if err := decodeQuery(r, &req); err != nil {
+			sendError(w, r, err)
+			return
+		}
+		opts, err := req.Options()
+		if err != nil {
+			sendError(w, r, err)
+			return
+		}
+
+		m, seat := lobby.Create(req.Name, requestLang(r), opts)
+		sendJSON(w, SeatResponse{MatchID: m.ID, Seat: 0, Token: seat.Token, Rules: opts.Rules}, http.StatusOK)
+	}
+}
+
+func lobbyJoinHandler(w http.ResponseWriter, r *http.Request) {
+	var req LobbyJoinRequest
+	if err := decodeQuery(r, &req); err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	m, ok := lobby.Get(req.Match)
+	if !ok {
+		sendError(w, r, errMatchNotFound)
+		return
+	}
+
+	seat, ok := m.Join(req.Name, requestLang(r))
+	if !ok {
+		sendError(w, r, errMatchFull)
+		return
+	}
+
+	sendJSON(w, SeatResponse{MatchID: m.ID, Seat: 1, Token: seat.Token, Rules: m.Options.Rules}, http.StatusOK)
+}
+
+func wsHandler(w http.ResponseWriter, r *http.Request) {
+	var req WSRequest
+	if err := decodeQuery(r, &req); err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	m, ok := lobby.Get(req.Match)
+	if !ok {
+		sendError(w, r, errMatchNotFound)
+		return
+	}
+
+	m.mu.Lock()
+	seat := m.seatIndex(req.Token)
+	m.mu.Unlock()
+	if seat == -1 {
+		sendError(w, r, errBadToken)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Println("Ошибка при открытии WebSocket:", err)
+		return
+	}
+
+	client := &wsClient{conn: conn, send: make(chan wsOutgoing, 32), lang: requestLang(r)}
+	go client.writeLoop()
+
+	m.mu.Lock()
+	if old := m.Seats[seat].client; old != nil {
+		close(old.send)
+	}
+	m.Seats[seat].client = client
+	m.LastSeen = time.Now()
+	m.broadcastState()
+	m.mu.Unlock()
+
+	for {
+		var in wsIncoming
+		if err := conn.ReadJSON(&in); err != nil {
+			break
+		}
+		m.handleMessage(seat, in)
+	}
+
+	m.mu.Lock()
+	if m.Seats[seat].client == client {
+		close(client.send)
+		m.Seats[seat].client = nil
+	}
+	m.mu.Unlock()
+}
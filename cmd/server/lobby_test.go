@@ -0,0 +1,75 @@
+package main
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"sea_battle/game"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// dialSeat подключает место партии к /ws; язык сообщений - английский
+func (c *specClient) dialSeat(seat jsonObject) *websocket.Conn {
+	c.t.Helper()
+	query := url.Values{"match": {seat["match_id"].(string)}, "token": {seat["token"].(string)}, "lang": {"en"}}
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(c.server.URL, "http")+"/api/ws?"+query.Encode(), nil)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	c.t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// nextOfType читает сообщения, пока не придет сообщение типа kind
+func nextOfType(t *testing.T, conn *websocket.Conn, kind string) jsonObject {
+	t.Helper()
+	for {
+		var msg jsonObject
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatal(err)
+		}
+		if msg["type"] == kind {
+			return msg
+		}
+	}
+}
+
+func TestLobbyMoveMessagesPerSeat(t *testing.T) {
+	c := newSpecClient(t)
+	first := c.call(http.MethodPost, "/lobby", url.Values{"lang": {"en"}}, nil, http.StatusOK)
+	second := c.call(http.MethodPost, "/lobby/join", url.Values{"match": {first["match_id"].(string)}, "lang": {"en"}}, nil, http.StatusOK)
+	shooter, target := c.dialSeat(first), c.dialSeat(second)
+
+	boards := make([]*game.Board, 2)
+	for i, conn := range []*websocket.Conn{shooter, target} {
+		boards[i] = game.NewBoard(game.DefaultRules())
+		if err := boards[i].PlaceBoard(rand.New(rand.NewPCG(uint64(i), 1))); err != nil {
+			t.Fatal(err)
+		}
+		if err := conn.WriteJSON(wsIncoming{Type: "place", Ships: boards[i].Ships}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	state := nextOfType(t, target, "state")
+	for state["game"] == nil {
+		state = nextOfType(t, target, "state")
+	}
+	if name := state["game"].(jsonObject)["me"].(jsonObject)["name"]; name != "Player 2" {
+		t.Errorf("имя второго места по умолчанию %v, ожидалось Player 2", name)
+	}
+
+	// первое место попадает в корабль второго и ходит еще раз
+	ship := boards[1].Ships[0].Position[0]
+	if err := shooter.WriteJSON(wsIncoming{Type: "attack", X: ship.X, Y: ship.Y}); err != nil {
+		t.Fatal(err)
+	}
+	if msg := nextOfType(t, shooter, "move")["message"]; msg != "Hit! You go again" && msg != "Ship sunk! You go again and get a new ability!" {
+		t.Errorf("стрелок получил %q", msg)
+	}
+	if msg := nextOfType(t, target, "move")["message"]; !strings.HasPrefix(msg.(string), "Your opponent") {
+		t.Errorf("соперник получил %q", msg)
+	}
+}
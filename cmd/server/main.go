@@ -1,31 +1,62 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
-	"sea_battle/game"
-	"sync"
+	"time"
 )
 
-var currentGame *game.Game
-var gameMutex = &sync.Mutex{}
-var saveExists bool
+var sessions *SessionManager
+var lobby *Lobby
+var saves *SaveSlots
 
-const saveFilename = "savegame.json"
+// debugMode открывает отладочные данные, например код идущей партии с флотом бота
+var debugMode bool
+
+// legacySaveFilename - единственный файл сохранения старых версий сервера
+const legacySaveFilename = "savegame.json"
 
 func main() {
-	_, err := os.Stat(saveFilename)
-	saveExists = !os.IsNotExist(err)
+	storeKind := flag.String("store", "fs", "хранилище сохранений: fs, sqlite или memory")
+	saveDir := flag.String("saves", "saves", "каталог для слотов сохранения в хранилище fs")
+	dbPath := flag.String("db", "sea_battle.db", "файл базы для хранилища sqlite")
+	saveKey := flag.String("save-key", os.Getenv("SEA_BATTLE_SAVE_KEY"), "ключ HMAC для подписи сохранений (по умолчанию из SEA_BATTLE_SAVE_KEY); пустой ключ отключает подпись")
+	importUnsigned := flag.Bool("import-unsigned", false, "перенести неподписанное старое сохранение "+legacySaveFilename+" и подписать его ключом сервера")
+	maxSessions := flag.Int("max-sessions", 1000, "сколько игр против бота может идти одновременно")
+	flag.BoolVar(&debugMode, "debug", false, "отдавать код идущей партии вместе с флотом бота")
+	flag.Parse()
+
+	store, err := newStore(*storeKind, *saveDir, *dbPath)
+	if err != nil {
+		log.Fatalf("Не удалось открыть хранилище сохранений: %v", err)
+	}
+	saves = &SaveSlots{Store: store, Key: []byte(*saveKey)}
+
+	imported, err := saves.ImportLegacy(legacySaveFilename, *importUnsigned)
+	if err != nil {
+		fmt.Println("Не удалось перенести старое сохранение:", err)
+	} else if imported {
+		fmt.Printf("Старое сохранение %s перенесено в слот %q\n", legacySaveFilename, defaultSlot)
+	}
+
+	if len(saves.Key) > 0 {
+		fmt.Println("Сохранения подписываются, файлы без верной подписи загружаться не будут")
+	}
 
-	if saveExists {
-		fmt.Println("Найден файл сохранения. Сервер готов к загрузке по запросу")
+	if saves.HasSaves(sharedOwner) {
+		fmt.Printf("Найдены сохранения в хранилище %s. Сервер готов к загрузке по запросу\n", *storeKind)
 	} else {
-		fmt.Println("Файл сохранения не найден")
+		fmt.Println("Сохранения не найдены")
 	}
 
-	currentGame = game.NewGame()
+	sessions = NewSessionManager(30*time.Minute, *maxSessions)
+	go sessions.RunJanitor(time.Minute)
+
+	lobby = NewLobby(time.Hour)
+	go lobby.RunJanitor(time.Minute)
 
 	router := newRouter()
 
@@ -6,8 +6,9 @@ import "net/http"
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Last-Event-ID, "+gameIDHeader+", "+saveOwnerHeader)
+		w.Header().Set("Access-Control-Expose-Headers", gameIDHeader+", "+saveOwnerHeader)
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -0,0 +1,362 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sea_battle/game"
+	"sea_battle/i18n"
+	"strings"
+	"sync"
+	"time"
+)
+
+// apiOperation - описание одного метода маршрута для OpenAPI
+type apiOperation struct {
+	Summary  string
+	Query    interface{} // структура запроса с тегами query, nil - без параметров
+	Body     interface{} // тело запроса в JSON
+	Response interface{} // ответ 200
+	Stream   bool        // ответ - поток text/event-stream
+	Upgrade  bool        // ответ - переход на WebSocket
+}
+
+// apiRoute - маршрут API: обработчик и разрешенные методы. Из этой же таблицы строится /api/openapi.json,
+// поэтому спецификация не расходится с тем, что сервер принимает на самом деле
+type apiRoute struct {
+	Path    string
+	Handler http.HandlerFunc
+	Methods map[string]apiOperation
+}
+
+func apiRoutes() []apiRoute {
+	return []apiRoute{
+		{"/games", gamesHandler, map[string]apiOperation{
+			http.MethodGet:  {Summary: "Список партий на сервере", Response: GamesResponse{}},
+			http.MethodPost: {Summary: "Новая сессия с партией против бота", Response: CreateGameResponse{}},
+		}},
+		{"/game", gameStatusHandler, map[string]apiOperation{
+			http.MethodGet: {Summary: "Состояние партии глазами игрока", Response: GameStatusResponse{}},
+		}},
+		{"/game/history", historyHandler, map[string]apiOperation{
+			http.MethodGet: {Summary: "Журнал партии или ее состояние на шаге at", Query: HistoryRequest{}, Response: HistoryResponse{}},
+		}},
+		{"/game/export", exportHandler, map[string]apiOperation{
+			http.MethodGet: {Summary: "Код позиции для баг-репорта", Response: ExportResponse{}},
+		}},
+		{"/game/import", importHandler, map[string]apiOperation{
+			http.MethodPost: {Summary: "Загрузить позицию из кода", Query: ImportRequest{}, Response: MessageResponse{}},
+		}},
+		{"/events", eventsHandler, map[string]apiOperation{
+			http.MethodGet: {Summary: "Поток событий партии (Server-Sent Events)", Stream: true},
+		}},
+		{"/rules", rulesHandler, map[string]apiOperation{
+			http.MethodGet: {Summary: "Наборы правил", Response: RulesResponse{}},
+		}},
+		{"/newgame/auto", newGameAutoHandler, map[string]apiOperation{
+			http.MethodPost: {Summary: "Новая партия со случайной расстановкой", Query: NewGameRequest{}, Response: MessageResponse{}},
+		}},
+		{"/newgame/manual", newGameManualHandler, map[string]apiOperation{
+			http.MethodPost: {Summary: "Новая партия с расстановкой игрока", Query: NewGameRequest{}, Body: ShipPlacementPayload{}, Response: MessageResponse{}},
+		}},
+		{"/attack", attackHandler, map[string]apiOperation{
+			http.MethodPost: {Summary: "Выстрел по полю бота", Query: CoordsRequest{}, Response: AttackResponse{}},
+		}},
+		{"/salvo", salvoHandler, map[string]apiOperation{
+			http.MethodPost: {Summary: "Залп по полю бота в режиме salvo", Body: SalvoRequest{}, Response: SalvoResponse{}},
+		}},
+		{"/abilities", abilitiesHandler, map[string]apiOperation{
+			http.MethodGet: {Summary: "Все способности игры", Response: AbilitiesResponse{}},
+		}},
+		{"/ability", abilityHandler, map[string]apiOperation{
+			http.MethodPost: {Summary: "Применить способность", Query: AbilityRequest{}, Response: AbilityResponse{}},
+		}},
+		{"/save", saveGameHandler, map[string]apiOperation{
+			http.MethodPost: {Summary: "Сохранить партию в слот", Query: SlotRequest{}, Response: SlotResponse{}},
+		}},
+		{"/load", loadGameHandler, map[string]apiOperation{
+			http.MethodPost: {Summary: "Загрузить партию из своего или общего слота", Query: LoadRequest{}, Response: SlotResponse{}},
+		}},
+		{"/saves", savesHandler, map[string]apiOperation{
+			http.MethodGet:    {Summary: "Свои и общие сохранения", Response: SavesResponse{}},
+			http.MethodDelete: {Summary: "Удалить свое сохранение", Query: SlotRequest{}, Response: SlotResponse{}},
+		}},
+		{"/lobby", lobbyHandler, map[string]apiOperation{
+			http.MethodGet:  {Summary: "Открытые партии двух игроков", Response: LobbyResponse{}},
+			http.MethodPost: {Summary: "Создать партию двух игроков", Query: LobbyCreateRequest{}, Response: SeatResponse{}},
+		}},
+		{"/lobby/join", lobbyJoinHandler, map[string]apiOperation{
+			http.MethodPost: {Summary: "Занять второе место в партии", Query: LobbyJoinRequest{}, Response: SeatResponse{}},
+		}},
+		{"/ws", wsHandler, map[string]apiOperation{
+			http.MethodGet: {Summary: "WebSocket партии двух игроков", Query: WSRequest{}, Upgrade: true},
+		}},
+		{"/openapi.json", openapiHandler, map[string]apiOperation{
+			http.MethodGet: {Summary: "Эта спецификация"},
+		}},
+	}
+}
+
+// allowMethods отвечает 405 на методы, которых нет в описании маршрута
+func allowMethods(route apiRoute) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := route.Methods[r.Method]; !ok {
+			sendError(w, r, errMethodNotAllowed)
+			return
+		}
+		route.Handler(w, r)
+	}
+}
+
+var (
+	openapiOnce sync.Once
+	openapiSpec []byte
+)
+
+func openapiHandler(w http.ResponseWriter, r *http.Request) {
+	openapiOnce.Do(func() {
+		openapiSpec, _ = json.MarshalIndent(buildOpenAPI(apiRoutes()), "", "  ")
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openapiSpec)
+}
+
+type jsonObject = map[string]interface{}
+
+func buildOpenAPI(routes []apiRoute) jsonObject {
+	schemas := schemaBuilder{components: jsonObject{}}
+	errorSchema := schemas.schema(reflect.TypeOf(ErrorResponse{}))
+
+	paths := jsonObject{}
+	for _, route := range routes {
+		item := jsonObject{}
+		for method, op := range route.Methods {
+			operation := jsonObject{"summary": op.Summary}
+
+			// язык ответа задается у любого метода
+			parameters := []jsonObject{
+				{"$ref": "#/components/parameters/lang"},
+				{"$ref": "#/components/parameters/Accept-Language"},
+			}
+			if op.Query != nil {
+				parameters = append(parameters, queryParameters(reflect.TypeOf(op.Query))...)
+			}
+			operation["parameters"] = parameters
+			if op.Body != nil {
+				operation["requestBody"] = jsonObject{
+					"required": true,
+					"content":  jsonObject{"application/json": jsonObject{"schema": schemas.schema(reflect.TypeOf(op.Body))}},
+				}
+			}
+
+			responses := jsonObject{
+				"default": jsonObject{
+					"description": "ошибка",
+					"content":     jsonObject{"application/json": jsonObject{"schema": errorSchema}},
+				},
+			}
+			switch {
+			case op.Stream:
+				responses["200"] = jsonObject{
+					"description": "поток событий",
+					"content":     jsonObject{"text/event-stream": jsonObject{"schema": jsonObject{"type": "string"}}},
+				}
+			case op.Upgrade:
+				responses["101"] = jsonObject{"description": "соединение переведено на WebSocket"}
+			case op.Response != nil:
+				responses["200"] = jsonObject{
+					"description": "успех",
+					"content":     jsonObject{"application/json": jsonObject{"schema": schemas.schema(reflect.TypeOf(op.Response))}},
+				}
+			default:
+				responses["200"] = jsonObject{"description": "успех"}
+			}
+			operation["responses"] = responses
+
+			item[strings.ToLower(method)] = operation
+		}
+		paths["/api"+route.Path] = item
+	}
+
+	return jsonObject{
+		"openapi":    "3.0.3",
+		"info":       jsonObject{"title": "Морской бой", "version": "1"},
+		"paths":      paths,
+		"components": jsonObject{"schemas": schemas.components, "parameters": langParameters()},
+	}
+}
+
+func langParameters() jsonObject {
+	langs := make([]string, 0)
+	for _, lang := range i18n.Langs() {
+		langs = append(langs, string(lang))
+	}
+	return jsonObject{
+		"lang": jsonObject{
+			"name":        "lang",
+			"in":          "query",
+			"description": "язык сообщений; важнее заголовка Accept-Language",
+			"schema":      jsonObject{"type": "string", "enum": langs},
+		},
+		"Accept-Language": jsonObject{
+			"name":        "Accept-Language",
+			"in":          "header",
+			"description": "предпочитаемые языки сообщений, по умолчанию " + string(i18n.Default),
+			"schema":      jsonObject{"type": "string"},
+		},
+	}
+}
+
+func queryParameters(t reflect.Type) []jsonObject {
+	var params []jsonObject
+	forEachQueryField(reflect.New(t).Elem(), func(field reflect.Value, name string, required bool) error {
+		ft := field.Type()
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		f, _ := fieldByQueryName(t, name)
+		params = append(params, jsonObject{
+			"name":        name,
+			"in":          "query",
+			"required":    required,
+			"description": f.Tag.Get("doc"),
+			"schema":      primitiveSchema(ft),
+		})
+		return nil
+	})
+	return params
+}
+
+// fieldByQueryName ищет поле с тегом query, в том числе во встроенных структурах, чтобы прочитать его doc
+func fieldByQueryName(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if found, ok := fieldByQueryName(f.Type, name); ok {
+				return found, true
+			}
+			continue
+		}
+		if tagName, _, _ := strings.Cut(f.Tag.Get("query"), ","); tagName == name {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	enumType       = reflect.TypeOf((*interface{ EnumValues() []string })(nil)).Elem()
+)
+
+func primitiveSchema(t reflect.Type) jsonObject {
+	switch t.Kind() {
+	case reflect.Bool:
+		return jsonObject{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return jsonObject{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return jsonObject{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return jsonObject{"type": "number"}
+	default:
+		return jsonObject{"type": "string"}
+	}
+}
+
+// schemaBuilder переводит типы Go в JSON Schema; именованные структуры попадают в components.schemas
+type schemaBuilder struct {
+	components jsonObject
+}
+
+func (b schemaBuilder) schema(t reflect.Type) jsonObject {
+	if t.Implements(enumType) {
+		values := reflect.Zero(t).Interface().(interface{ EnumValues() []string }).EnumValues()
+		return jsonObject{"type": "string", "enum": values}
+	}
+
+	switch {
+	case t == timeType:
+		return jsonObject{"type": "string", "format": "date-time"}
+	case t == rawMessageType:
+		return jsonObject{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return b.schema(t.Elem())
+	case reflect.Interface:
+		return jsonObject{}
+	case reflect.Slice, reflect.Array:
+		return jsonObject{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return jsonObject{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t)
+		}
+		name := schemaName(t)
+		if _, ok := b.components[name]; !ok {
+			b.components[name] = jsonObject{} // заглушка на случай рекурсивных типов
+			b.components[name] = b.object(t)
+		}
+		return jsonObject{"$ref": "#/components/schemas/" + name}
+	default:
+		return primitiveSchema(t)
+	}
+}
+
+// schemaName различает одноименные типы пакетов game и main
+func schemaName(t reflect.Type) string {
+	if t.PkgPath() == reflect.TypeOf(game.Game{}).PkgPath() {
+		return "game." + t.Name()
+	}
+	return t.Name()
+}
+
+func (b schemaBuilder) object(t reflect.Type) jsonObject {
+	properties := jsonObject{}
+	var required []string
+	b.collectFields(t, properties, &required)
+
+	obj := jsonObject{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		obj["required"] = required
+	}
+	return obj
+}
+
+// collectFields читает поля так же, как encoding/json: по тегу json, встроенные структуры раскрываются
+func (b schemaBuilder) collectFields(t reflect.Type, properties jsonObject, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				b.collectFields(ft, properties, required)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		properties[name] = b.schema(f.Type)
+		if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Pointer {
+			*required = append(*required, name)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"sea_battle/game"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// specClient вызывает API тестового сервера и сверяет каждый ответ со спецификацией из buildOpenAPI
+type specClient struct {
+	t       *testing.T
+	server  *httptest.Server
+	client  *http.Client
+	spec    jsonObject
+	covered map[string]bool // "METHOD /path" вызванных операций
+}
+
+func newSpecClient(t *testing.T) *specClient {
+	// спецификация проходит через JSON, как ее видят клиенты: перечисления - строки, $ref - ссылки
+	data, err := json.Marshal(buildOpenAPI(apiRoutes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spec jsonObject
+	if err := json.Unmarshal(data, &spec); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions = NewSessionManager(time.Minute, 10)
+	saves = &SaveSlots{Store: game.NewMemoryStore()}
+	lobby = NewLobby(time.Hour)
+
+	jar, _ := cookiejar.New(nil)
+	server := httptest.NewServer(newRouter())
+	t.Cleanup(server.Close)
+	return &specClient{t: t, server: server, client: &http.Client{Jar: jar}, spec: spec, covered: map[string]bool{}}
+}
+
+// call выполняет запрос, проверяет статус и схему ответа и возвращает разобранное тело
+func (c *specClient) call(method, path string, query url.Values, body interface{}, status int) jsonObject {
+	c.t.Helper()
+	c.covered[method+" "+path] = true
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			c.t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.server.URL+"/api"+path+"?"+query.Encode(), reader)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+
+	if resp.StatusCode != status {
+		c.t.Fatalf("%s %s: статус %d, ожидался %d: %s", method, path, resp.StatusCode, status, data)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		c.t.Fatalf("%s %s: ответ не JSON: %v", method, path, err)
+	}
+
+	if schema := c.responseSchema(method, path, status); schema != nil {
+		for _, problem := range c.validate(schema, decoded, "ответ") {
+			c.t.Errorf("%s %s: %s", method, path, problem)
+		}
+	}
+	obj, _ := decoded.(jsonObject)
+	return obj
+}
+
+// responseSchema - схема ответа с этим статусом или ответа default, nil - если у ответа нет тела
+func (c *specClient) responseSchema(method, path string, status int) jsonObject {
+	c.t.Helper()
+	item, _ := c.spec["paths"].(jsonObject)["/api"+path].(jsonObject)
+	operation, ok := item[strings.ToLower(method)].(jsonObject)
+	if !ok {
+		// на методы, которых нет в спецификации, сервер отвечает обычной ошибкой
+		return jsonObject{"$ref": "#/components/schemas/ErrorResponse"}
+	}
+	responses := operation["responses"].(jsonObject)
+	response, ok := responses[strconv.Itoa(status)].(jsonObject)
+	if !ok {
+		response = responses["default"].(jsonObject)
+	}
+	content, _ := response["content"].(jsonObject)
+	media, _ := content["application/json"].(jsonObject)
+	schema, _ := media["schema"].(jsonObject)
+	return schema
+}
+
+// validate сверяет значение со схемой: типы, обязательные поля, значения перечислений и лишние поля
+func (c *specClient) validate(schema jsonObject, value interface{}, where string) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		return c.validate(c.spec["components"].(jsonObject)["schemas"].(jsonObject)[name].(jsonObject), value, where+" ("+name+")")
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		for _, allowed := range enum {
+			if value == allowed {
+				return nil
+			}
+		}
+		return []string{fmt.Sprintf("%s: значение %v не входит в перечисление %v", where, value, enum)}
+	}
+
+	var problems []string
+	mismatch := func(want string) []string {
+		return []string{fmt.Sprintf("%s: ожидался %s, получено %T %v", where, want, value, value)}
+	}
+	switch schema["type"] {
+	case "object":
+		obj, ok := value.(jsonObject)
+		if !ok {
+			return mismatch("объект")
+		}
+		required := map[string]bool{}
+		if names, ok := schema["required"].([]interface{}); ok {
+			for _, name := range names {
+				required[name.(string)] = true
+				if _, ok := obj[name.(string)]; !ok {
+					problems = append(problems, fmt.Sprintf("%s: нет обязательного поля %s", where, name))
+				}
+			}
+		}
+		properties, _ := schema["properties"].(jsonObject)
+		extra, _ := schema["additionalProperties"].(jsonObject)
+		for name, field := range obj {
+			switch prop, ok := properties[name].(jsonObject); {
+			case ok:
+				// необязательные поля-указатели сериализуются как null
+				if field == nil && !required[name] {
+					continue
+				}
+				problems = append(problems, c.validate(prop, field, where+"."+name)...)
+			case extra != nil:
+				problems = append(problems, c.validate(extra, field, where+"."+name)...)
+			default:
+				problems = append(problems, fmt.Sprintf("%s: поля %s нет в схеме", where, name))
+			}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return mismatch("массив")
+		}
+		for i, item := range items {
+			problems = append(problems, c.validate(schema["items"].(jsonObject), item, fmt.Sprintf("%s[%d]", where, i))...)
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return mismatch("string")
+		}
+	case "integer":
+		if n, ok := value.(float64); !ok || n != math.Trunc(n) {
+			return mismatch("integer")
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return mismatch("number")
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return mismatch("boolean")
+		}
+	}
+	return problems
+}
+
+// session возвращает партию, которую сервер выдал клиенту теста
+func (c *specClient) session(gameID string) *Session {
+	c.t.Helper()
+	s, ok := sessions.Get(gameID)
+	if !ok {
+		c.t.Fatalf("сессия %s не найдена", gameID)
+	}
+	return s
+}
+
+func TestAPIMatchesOpenAPI(t *testing.T) {
+	c := newSpecClient(t)
+
+	// без сессии партию не видно и новая не создается
+	c.call(http.MethodGet, "/game", nil, nil, http.StatusNotFound)
+	c.call(http.MethodGet, "/events", nil, nil, http.StatusNotFound)
+
+	created := c.call(http.MethodPost, "/games", nil, nil, http.StatusOK)
+	gameID := created["game_id"].(string)
+	c.call(http.MethodGet, "/games", nil, nil, http.StatusOK)
+	c.call(http.MethodGet, "/game", nil, nil, http.StatusOK)
+	c.call(http.MethodDelete, "/game", nil, nil, http.StatusMethodNotAllowed)
+	c.call(http.MethodGet, "/rules", nil, nil, http.StatusOK)
+	c.call(http.MethodGet, "/abilities", nil, nil, http.StatusOK)
+	c.call(http.MethodGet, "/openapi.json", nil, nil, http.StatusOK)
+
+	c.call(http.MethodPost, "/newgame/auto", url.Values{"difficulty": {"easy"}, "seed": {"1"}}, nil, http.StatusOK)
+	s := c.session(gameID)
+	scanner, err := game.NewAbility("scanner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.mu.Lock()
+	s.Game.Player1.Abilities = append(s.Game.Player1.Abilities, scanner)
+	s.mu.Unlock()
+	c.call(http.MethodPost, "/ability", url.Values{"ability_name": {"scanner"}, "x": {"4"}, "y": {"4"}}, nil, http.StatusOK)
+	c.call(http.MethodPost, "/ability", url.Values{"ability_name": {"scanner"}, "x": {"4"}, "y": {"4"}}, nil, http.StatusNotFound)
+	c.call(http.MethodPost, "/attack", url.Values{"x": {"99"}, "y": {"0"}}, nil, http.StatusBadRequest)
+	c.call(http.MethodPost, "/attack", url.Values{"x": {"0"}, "y": {"0"}}, nil, http.StatusOK)
+
+	c.call(http.MethodGet, "/game/history", nil, nil, http.StatusOK)
+	c.call(http.MethodGet, "/game/history", url.Values{"at": {"1"}}, nil, http.StatusOK)
+	c.call(http.MethodGet, "/game/export", nil, nil, http.StatusOK)
+	s.mu.Lock()
+	code, err := game.EncodeGameCode(s.Game)
+	s.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.call(http.MethodPost, "/game/import", url.Values{"code": {code}}, nil, http.StatusOK)
+
+	c.call(http.MethodPost, "/save", url.Values{"slot": {"spec"}}, nil, http.StatusOK)
+	c.call(http.MethodGet, "/saves", nil, nil, http.StatusOK)
+	c.call(http.MethodPost, "/load", url.Values{"slot": {"spec"}}, nil, http.StatusOK)
+	c.call(http.MethodDelete, "/saves", url.Values{"slot": {"spec"}}, nil, http.StatusOK)
+	c.call(http.MethodPost, "/load", url.Values{"slot": {"spec"}}, nil, http.StatusNotFound)
+
+	board := game.NewBoard(game.DefaultRules())
+	if err := board.PlaceBoard(rand.New(rand.NewPCG(1, 2))); err != nil {
+		t.Fatal(err)
+	}
+	c.call(http.MethodPost, "/newgame/manual", nil, ShipPlacementPayload{Ships: board.Ships}, http.StatusOK)
+	c.call(http.MethodPost, "/newgame/manual", nil, ShipPlacementPayload{Ships: board.Ships[:1]}, http.StatusUnprocessableEntity)
+
+	c.call(http.MethodPost, "/newgame/auto", url.Values{"mode": {"salvo"}, "seed": {"1"}}, nil, http.StatusOK)
+	status := c.call(http.MethodGet, "/game", nil, nil, http.StatusOK)
+	var shots []game.Point
+	for i := 0; i < int(status["game"].(jsonObject)["shots_per_turn"].(float64)); i++ {
+		shots = append(shots, game.Point{X: i / 10, Y: i % 10})
+	}
+	c.call(http.MethodPost, "/salvo", nil, SalvoRequest{Shots: shots[:1]}, http.StatusBadRequest)
+	c.call(http.MethodPost, "/salvo", nil, SalvoRequest{Shots: shots}, http.StatusOK)
+
+	c.checkEvents()
+
+	seat := c.call(http.MethodPost, "/lobby", url.Values{"name": {"Алиса"}}, nil, http.StatusOK)
+	matchID := seat["match_id"].(string)
+	c.call(http.MethodGet, "/lobby", nil, nil, http.StatusOK)
+	c.call(http.MethodPost, "/lobby/join", url.Values{"match": {matchID}, "name": {"Боб"}}, nil, http.StatusOK)
+	c.call(http.MethodPost, "/lobby/join", url.Values{"match": {matchID}, "name": {"Ева"}}, nil, http.StatusConflict)
+	c.checkWebSocket(matchID, seat["token"].(string))
+
+	for _, route := range apiRoutes() {
+		for method := range route.Methods {
+			if !c.covered[method+" "+route.Path] {
+				t.Errorf("тест не вызывает %s %s", method, route.Path)
+			}
+		}
+	}
+}
+
+// checkEvents проверяет, что /events отвечает потоком text/event-stream
+func (c *specClient) checkEvents() {
+	c.t.Helper()
+	c.covered["GET /events"] = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.server.URL+"/api/events", nil)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		c.t.Errorf("GET /events: статус %d, Content-Type %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+}
+
+// checkWebSocket проверяет, что /ws переводит соединение на WebSocket
+func (c *specClient) checkWebSocket(matchID, token string) {
+	c.t.Helper()
+	c.covered["GET /ws"] = true
+
+	query := url.Values{"match": {matchID}, "token": {token}}
+	wsURL := "ws" + strings.TrimPrefix(c.server.URL, "http") + "/api/ws?" + query.Encode()
+	conn, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		c.t.Fatalf("GET /ws: %v", err)
+	}
+	defer conn.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		c.t.Errorf("GET /ws: статус %d", resp.StatusCode)
+	}
+}
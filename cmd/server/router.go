@@ -6,13 +6,9 @@ func newRouter() *http.ServeMux {
 	mux := http.NewServeMux()
 
 	apiMux := http.NewServeMux()
-	apiMux.HandleFunc("/game", gameStatusHandler)
-	apiMux.HandleFunc("/newgame/auto", newGameAutoHandler)
-	apiMux.HandleFunc("/newgame/manual", newGameManualHandler)
-	apiMux.HandleFunc("/attack", attackHandler)
-	apiMux.HandleFunc("/ability", abilityHandler)
-	apiMux.HandleFunc("/save", saveGameHandler)
-	apiMux.HandleFunc("/load", loadGameHandler)
+	for _, route := range apiRoutes() {
+		apiMux.HandleFunc(route.Path, allowMethods(route))
+	}
 
 	mux.Handle("/api/", http.StripPrefix("/api", apiMux))
 
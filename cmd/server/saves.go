@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sea_battle/game"
+	"sort"
+	"strings"
+	"time"
+)
+
+const defaultSlot = "default"
+
+// sharedOwner - владелец общих слотов, куда попадает и перенесенное старое сохранение.
+// Общие слоты видны всем только для загрузки
+const sharedOwner = ""
+
+const (
+	saveOwnerCookie = "sea_battle_owner"
+	saveOwnerHeader = "X-Save-Owner"
+)
+
+// SaveInfo - краткие сведения о сохранении для списка слотов
+type SaveInfo struct {
+	Slot      string         `json:"slot"`
+	SavedAt   time.Time      `json:"saved_at"`
+	Turns     int            `json:"turns"`
+	ShipsLeft map[string]int `json:"ships_left"`
+	Winner    string         `json:"winner,omitempty"`
+	Shared    bool           `json:"shared,omitempty"` // общий слот: загрузить можно, удалить нельзя
+}
+
+// SaveSlots - сохранения сервера: хранилище, выбранное флагом, и ключ подписи.
+// Если задан Key, сохранения подписываются и записи с неверной подписью не загружаются
+type SaveSlots struct {
+	Store game.Store
+	Key   []byte
+}
+
+// newStore создает хранилище по имени из флага -store
+func newStore(kind, dir, dbPath string) (game.Store, error) {
+	switch kind {
+	case "fs":
+		return game.NewFileStore(dir)
+	case "sqlite":
+		return game.NewSQLiteStore(dbPath)
+	case "memory":
+		return game.NewMemoryStore(), nil
+	}
+	return nil, fmt.Errorf("неизвестное хранилище %q: ожидается fs, sqlite или memory", kind)
+}
+
+// normalizeSlot проверяет имя слота; пустое имя означает слот по умолчанию
+func normalizeSlot(slot string) (string, error) {
+	slot = strings.TrimSpace(slot)
+	if slot == "" {
+		return defaultSlot, nil
+	}
+	return slot, game.ValidateSlot(slot)
+}
+
+// saveOwnerFromRequest возвращает владельца слотов клиента из заголовка или cookie, а новому клиенту
+// выдает его. Владелец не совпадает с ID партии: партия истекает через ttl, а сохранения остаются
+func saveOwnerFromRequest(w http.ResponseWriter, r *http.Request) string {
+	owner := r.Header.Get(saveOwnerHeader)
+	if owner == "" {
+		if cookie, err := r.Cookie(saveOwnerCookie); err == nil {
+			owner = cookie.Value
+		}
+	}
+	if raw, err := hex.DecodeString(owner); err != nil || len(raw) != 16 {
+		owner = newSessionID()
+	}
+
+	w.Header().Set(saveOwnerHeader, owner)
+	http.SetCookie(w, &http.Cookie{
+		Name:     saveOwnerCookie,
+		Value:    owner,
+		Path:     "/",
+		MaxAge:   int((365 * 24 * time.Hour).Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return owner
+}
+
+func (s *SaveSlots) Save(owner, slot string, g *game.Game) error {
+	return g.SaveGame(s.Store, owner, slot, s.Key)
+}
+
+func (s *SaveSlots) Load(owner, slot string) (*game.Game, error) {
+	return game.LoadGame(s.Store, owner, slot, s.Key)
+}
+
+func (s *SaveSlots) Delete(owner, slot string) error {
+	return s.Store.Delete(owner, slot)
+}
+
+// List возвращает сведения о слотах владельца и об общих слотах, новые сохранения идут первыми.
+// Записи, которые не удалось прочитать как партию, пропускаются
+func (s *SaveSlots) List(owner string) ([]SaveInfo, error) {
+	infos, err := s.list(owner)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := s.list(sharedOwner)
+	if err != nil {
+		return nil, err
+	}
+	infos = append(infos, shared...)
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].SavedAt.After(infos[j].SavedAt) })
+	return infos, nil
+}
+
+func (s *SaveSlots) list(owner string) ([]SaveInfo, error) {
+	stored, err := s.Store.List(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := []SaveInfo{}
+	for _, save := range stored {
+		data, err := s.Store.Load(owner, save.Slot)
+		if err != nil {
+			continue
+		}
+		g, err := game.DecodeGame(data, s.Key)
+		if err != nil {
+			fmt.Printf("Пропущено поврежденное сохранение %s: %v\n", save.Slot, err)
+			continue
+		}
+
+		infos = append(infos, SaveInfo{
+			Slot:    save.Slot,
+			SavedAt: save.SavedAt,
+			Turns:   g.TurnCount(),
+			ShipsLeft: map[string]int{
+				g.Player1.Name: g.Player1.MyBoard.ShipsLeft(),
+				g.Player2.Name: g.Player2.MyBoard.ShipsLeft(),
+			},
+			Winner: g.Winner,
+			Shared: owner == sharedOwner,
+		})
+	}
+	return infos, nil
+}
+
+// HasSaves сообщает, есть ли у владельца или среди общих хотя бы один слот, не читая сами партии
+func (s *SaveSlots) HasSaves(owner string) bool {
+	for _, o := range []string{owner, sharedOwner} {
+		if stored, err := s.Store.List(o); err == nil && len(stored) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ImportLegacy переносит единственное сохранение старых версий сервера в слот по умолчанию,
+// заодно переводя его в текущий формат и подписывая. Исходный файл не трогается,
+// а уже существующий слот не перезаписывается.
+// Старый файл не подписан, и подпись ключом сервера сделала бы доверенным любой правленый JSON,
+// поэтому при заданном Key файл переносится, только если оператор разрешил это явно (trustUnsigned)
+func (s *SaveSlots) ImportLegacy(filename string, trustUnsigned bool) (bool, error) {
+	if _, err := s.Store.Load(sharedOwner, defaultSlot); !errors.Is(err, game.ErrSaveNotFound) {
+		return false, err
+	}
+
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if len(s.Key) > 0 && !trustUnsigned {
+		return false, errUnsignedLegacy
+	}
+
+	// старые сохранения никогда не подписывались; DecodeGame проверяет партию до подписи
+	g, err := game.DecodeGame(data, nil)
+	if err != nil {
+		return false, err
+	}
+	return true, g.SaveGame(s.Store, sharedOwner, defaultSlot, s.Key)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sea_battle/game"
+	"testing"
+)
+
+// writeLegacySave записывает неподписанное сохранение, как его оставляли старые версии сервера
+func writeLegacySave(t *testing.T) string {
+	t.Helper()
+	store := game.NewMemoryStore()
+	if err := newDefaultGame().SaveGame(store, sharedOwner, "legacy", nil); err != nil {
+		t.Fatal(err)
+	}
+	data, err := store.Load(sharedOwner, "legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(t.TempDir(), legacySaveFilename)
+	if err := os.WriteFile(filename, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestImportLegacyNeedsOptInWithKey(t *testing.T) {
+	filename := writeLegacySave(t)
+	slots := &SaveSlots{Store: game.NewMemoryStore(), Key: []byte("secret")}
+
+	if imported, err := slots.ImportLegacy(filename, false); imported || !errors.Is(err, errUnsignedLegacy) {
+		t.Fatalf("без разрешения: imported=%v, err=%v", imported, err)
+	}
+	if slots.HasSaves(sharedOwner) {
+		t.Fatal("неподписанное сохранение перенесено без разрешения")
+	}
+
+	if imported, err := slots.ImportLegacy(filename, true); !imported || err != nil {
+		t.Fatalf("с разрешением: imported=%v, err=%v", imported, err)
+	}
+	if _, err := slots.Load(sharedOwner, defaultSlot); err != nil {
+		t.Fatalf("перенесенное сохранение не загружается: %v", err)
+	}
+}
+
+func TestImportLegacyWithoutKey(t *testing.T) {
+	slots := &SaveSlots{Store: game.NewMemoryStore()}
+	if imported, err := slots.ImportLegacy(writeLegacySave(t), false); !imported || err != nil {
+		t.Fatalf("imported=%v, err=%v", imported, err)
+	}
+}
+
+func TestSaveSlotsArePerClient(t *testing.T) {
+	alice := newSpecClient(t)
+	jar, _ := cookiejar.New(nil)
+	bob := &specClient{t: t, server: alice.server, client: &http.Client{Jar: jar}, spec: alice.spec, covered: map[string]bool{}}
+	if err := newDefaultGame().SaveGame(saves.Store, sharedOwner, "old", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	slot := url.Values{"slot": {"mine"}}
+	alice.call(http.MethodPost, "/games", nil, nil, http.StatusOK)
+	alice.call(http.MethodPost, "/save", slot, nil, http.StatusOK)
+	bob.call(http.MethodPost, "/games", nil, nil, http.StatusOK)
+
+	listed := bob.call(http.MethodGet, "/saves", nil, nil, http.StatusOK)["saves"].([]interface{})
+	if len(listed) != 1 || listed[0].(jsonObject)["slot"] != "old" || listed[0].(jsonObject)["shared"] != true {
+		t.Fatalf("Боб видит слоты %v, ожидался только общий old", listed)
+	}
+	bob.call(http.MethodPost, "/load", slot, nil, http.StatusNotFound)
+	bob.call(http.MethodDelete, "/saves", slot, nil, http.StatusNotFound)
+	bob.call(http.MethodPost, "/load", url.Values{"slot": {"old"}, "shared": {"true"}}, nil, http.StatusOK)
+	bob.call(http.MethodDelete, "/saves", url.Values{"slot": {"old"}}, nil, http.StatusNotFound)
+
+	alice.call(http.MethodPost, "/load", slot, nil, http.StatusOK)
+	alice.call(http.MethodDelete, "/saves", slot, nil, http.StatusOK)
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sea_battle/game"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	gameIDCookie = "sea_battle_game"
+	gameIDHeader = "X-Game-ID"
+)
+
+// Session - отдельная партия одного клиента со своей блокировкой
+type Session struct {
+	ID        string
+	Game      *game.Game
+	CreatedAt time.Time
+	LastSeen  time.Time
+
+	mu         sync.Mutex
+	events     *eventHub
+	generation int // номер партии в сессии, входит в ID событий потока
+
+	// воркер ходов бота живет, пока не отменен ctx сессии
+	ctx       context.Context
+	cancel    context.CancelFunc
+	botJobs   chan botJob
+	botCancel context.CancelFunc
+}
+
+// SessionInfo - открытые сведения о партии: ее идентификатор служит ключом доступа
+// и в список не попадает
+type SessionInfo struct {
+	CreatedAt     time.Time `json:"created_at"`
+	LastSeen      time.Time `json:"last_seen"`
+	CurrentPlayer string    `json:"current_player"`
+}
+
+type SessionManager struct {
+	mu       sync.RWMutex
+	sessions map[string]*Session
+	ttl      time.Duration
+	limit    int
+}
+
+// NewSessionManager создает хранилище партий; limit ограничивает число одновременных партий
+func NewSessionManager(ttl time.Duration, limit int) *SessionManager {
+	return &SessionManager{
+		sessions: make(map[string]*Session),
+		ttl:      ttl,
+		limit:    limit,
+	}
+}
+
+func newSessionID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		panic("не удалось сгенерировать идентификатор игры: " + err.Error())
+	}
+	return hex.EncodeToString(buf)
+}
+
+// Create заводит сессию с партией g, если не превышен лимит одновременных партий
+func (m *SessionManager) Create(g *game.Game) (*Session, error) {
+	now := time.Now()
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Session{
+		ID:        newSessionID(),
+		CreatedAt: now,
+		LastSeen:  now,
+		events:    newEventHub(),
+		ctx:       ctx,
+		cancel:    cancel,
+		botJobs:   make(chan botJob, 1),
+	}
+	s.SetGame(g)
+
+	// проверка лимита и добавление под одной блокировкой, иначе параллельные запросы его превысят
+	m.mu.Lock()
+	if len(m.sessions) >= m.limit {
+		m.mu.Unlock()
+		cancel()
+		return nil, errTooManySessions
+	}
+	m.sessions[s.ID] = s
+	m.mu.Unlock()
+
+	go s.runBotWorker()
+	return s, nil
+}
+
+// SetGame заменяет партию сессии, прерывает ход бота в прежней партии и подписывает
+// поток событий на журнал новой. Если в новой партии ходит бот, его ход запускается сразу.
+// Вызывается под s.mu, как и любое другое изменение партии
+func (s *Session) SetGame(g *game.Game) {
+	replaced := s.Game != nil
+	s.cancelBotTurn()
+	s.Game = g
+	s.generation++
+	generation := s.generation
+	g.SetEventListener(func(e game.Event) {
+		s.events.publish(sseMessages(generation, g, e)...)
+	})
+	if replaced {
+		s.events.publish(gameResetMessage(generation))
+	}
+	if !g.IsOver() && g.CurrentPlayer == g.Player2 && g.Player2.Strategy != nil {
+		s.startBotTurn()
+	}
+}
+
+func (m *SessionManager) Get(id string) (*Session, bool) {
+	if id == "" {
+		return nil, false
+	}
+
+	m.mu.RLock()
+	s, ok := m.sessions[id]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+
+	s.mu.Lock()
+	s.LastSeen = time.Now()
+	s.mu.Unlock()
+	return s, true
+}
+
+func (m *SessionManager) List() []SessionInfo {
+	m.mu.RLock()
+	sessions := make([]*Session, 0, len(m.sessions))
+	for _, s := range m.sessions {
+		sessions = append(sessions, s)
+	}
+	m.mu.RUnlock()
+
+	infos := make([]SessionInfo, 0, len(sessions))
+	for _, s := range sessions {
+		s.mu.Lock()
+		infos = append(infos, SessionInfo{
+			CreatedAt:     s.CreatedAt,
+			LastSeen:      s.LastSeen,
+			CurrentPlayer: s.Game.CurrentPlayer.Name,
+		})
+		s.mu.Unlock()
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].CreatedAt.Before(infos[j].CreatedAt)
+	})
+	return infos
+}
+
+// Expire удаляет партии, к которым не обращались дольше ttl
+func (m *SessionManager) Expire() int {
+	deadline := time.Now().Add(-m.ttl)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	removed := 0
+	for id, s := range m.sessions {
+		s.mu.Lock()
+		expired := s.LastSeen.Before(deadline)
+		s.mu.Unlock()
+		if expired {
+			s.cancel()
+			s.events.closeAll()
+			delete(m.sessions, id)
+			removed++
+		}
+	}
+	return removed
+}
+
+func (m *SessionManager) RunJanitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		m.Expire()
+	}
+}
+
+// sessionFromRequest находит партию клиента по заголовку или cookie. Если партии нет,
+// отвечает ошибкой и возвращает nil: сессии создают только запросы новой игры (startGame)
+func sessionFromRequest(w http.ResponseWriter, r *http.Request) *Session {
+	s, ok := sessions.Get(requestSessionID(r))
+	if !ok {
+		sendError(w, r, errSessionNotFound)
+		return nil
+	}
+	attachSession(w, s)
+	return s
+}
+
+// startGame ставит новую партию g в сессию клиента, а если сессии нет - создает ее сразу с этой партией.
+// Возвращает nil, если ответ с ошибкой уже отправлен
+func startGame(w http.ResponseWriter, r *http.Request, g *game.Game) *Session {
+	s, ok := sessions.Get(requestSessionID(r))
+	if ok {
+		s.mu.Lock()
+		s.SetGame(g)
+		s.mu.Unlock()
+	} else {
+		var err error
+		if s, err = sessions.Create(g); err != nil {
+			sendError(w, r, err)
+			return nil
+		}
+	}
+	attachSession(w, s)
+	return s
+}
+
+func requestSessionID(r *http.Request) string {
+	if id := r.Header.Get(gameIDHeader); id != "" {
+		return id
+	}
+	if cookie, err := r.Cookie(gameIDCookie); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+func attachSession(w http.ResponseWriter, s *Session) {
+	w.Header().Set(gameIDHeader, s.ID)
+	http.SetCookie(w, &http.Cookie{
+		Name:     gameIDCookie,
+		Value:    s.ID,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
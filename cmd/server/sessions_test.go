@@ -0,0 +1,27 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSessionManagerCreate(t *testing.T) {
+	m := NewSessionManager(time.Minute, 1)
+	g := newDefaultGame()
+	s, err := m.Create(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.cancel()
+	if s.Game != g {
+		t.Fatal("сессия создана не с переданной партией")
+	}
+
+	if _, err := m.Create(newDefaultGame()); !errors.Is(err, errTooManySessions) {
+		t.Fatalf("сверх лимита: %v, ожидалась errTooManySessions", err)
+	}
+	if len(m.List()) != 1 {
+		t.Fatalf("в списке %d сессий", len(m.List()))
+	}
+}
@@ -1,17 +1,16 @@
 package game
 
 import (
-	"errors"
 	"fmt"
-	"math/rand"
+	"sea_battle/i18n"
 )
 
-func (a *ArtilleryStrike) Apply(g *Game, target *Point) (*AbilityResult, error) {
+func (a *ArtilleryStrike) Apply(g *Game, target *AbilityTarget) (*AbilityResult, error) {
 	enemyBoard := g.CurrentPlayer.EnemyBoard
 	availableTargets := []Point{}
 
-	for i := 0; i < 10; i++ {
-		for j := 0; j < 10; j++ {
+	for i := range enemyBoard.Grid {
+		for j := range enemyBoard.Grid[i] {
 			cellStatus := enemyBoard.Grid[i][j]
 			if cellStatus == ShipCell || cellStatus == EmptyCell {
 				availableTargets = append(availableTargets, Point{X: i, Y: j})
@@ -20,43 +19,45 @@ func (a *ArtilleryStrike) Apply(g *Game, target *Point) (*AbilityResult, error)
 	}
 
 	if len(availableTargets) == 0 {
-		return &AbilityResult{Message: "Нет целей для артиллерийского удара"}, nil
+		return &AbilityResult{Message: i18n.M("ability.artillery_strike.none")}, nil
 	}
 
-	randomPointInd := rand.Intn(len(availableTargets))
+	randomPointInd := g.Rand().IntN(len(availableTargets))
 	randomPoint := availableTargets[randomPointInd]
 
-	result, markedPoints, err := enemyBoard.Attack(&randomPoint, g.CurrentPlayer)
+	attack, err := g.attack(g.CurrentPlayer, randomPoint)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка при использовании артиллерийского удара: %w", err)
 	}
 
-	if result == ResultSunk {
-		g.CurrentPlayer.AddRandomAbility()
+	if attack.Result == ResultSunk {
+		g.GrantRandomAbility(g.CurrentPlayer)
 	}
 
-	msg := fmt.Sprintf("Артиллерийский удар нанесен по (%d, %d)", randomPoint.X, randomPoint.Y)
+	msg := i18n.Message{ID: "ability.artillery_strike.done", Params: i18n.Params{"x": randomPoint.X, "y": randomPoint.Y}}
 	return &AbilityResult{
-		Message: msg,
-		AttackResult: &AttackResultData{
-			Target:       randomPoint,
-			Result:       result,
-			MarkedPoints: markedPoints,
-		},
+		Message:       msg,
+		AttackResults: []*AttackResultData{attack},
 	}, nil
 }
 
-func (a *ArtilleryStrike) Name() string {
-	return "Артиллерийский удар"
+func (a *ArtilleryStrike) ID() string {
+	return "artillery_strike"
 }
 
-func (a *ArtilleryStrike) RequiresTarget() bool {
-	return false
+func init() {
+	RegisterAbility(AbilityInfo{
+		ID:      "artillery_strike",
+		Name:    i18n.M("ability.artillery_strike"),
+		New:     func() Ability { return &ArtilleryStrike{} },
+		Weight:  1,
+		Aliases: []string{"Артиллерийский удар"},
+	})
 }
 
-func (s *Scanner) Apply(g *Game, target *Point) (*AbilityResult, error) {
+func (s *Scanner) Apply(g *Game, target *AbilityTarget) (*AbilityResult, error) {
 	if target == nil {
-		return nil, errors.New("для сканера необходимо указать координаты")
+		return nil, fmt.Errorf("%w: для сканера необходимо указать координаты", ErrNeedTarget)
 	}
 
 	enemyBoard := g.CurrentPlayer.EnemyBoard
@@ -67,7 +68,7 @@ func (s *Scanner) Apply(g *Game, target *Point) (*AbilityResult, error) {
 		for dy := -1; dy <= 1; dy++ {
 			checkX, checkY := target.X+dx, target.Y+dy
 			candidate := Point{X: checkX, Y: checkY}
-			if candidate.IsValidPoint() {
+			if enemyBoard.IsValidPoint(candidate) {
 				affectedPoints = append(affectedPoints, candidate)
 				if enemyBoard.Grid[checkX][checkY] == ShipCell {
 					countShips++
@@ -76,37 +77,344 @@ func (s *Scanner) Apply(g *Game, target *Point) (*AbilityResult, error) {
 		}
 	}
 
-	msg := fmt.Sprintf("Сканирование области 3x3 в точке (%d, %d). Обнаружено %d сегментов кораблей", target.X, target.Y, countShips)
+	msg := i18n.Message{ID: "ability.scanner.done", Params: i18n.Params{"x": target.X, "y": target.Y, "count": countShips}}
 	return &AbilityResult{
 		Message:        msg,
 		AffectedPoints: affectedPoints,
 	}, nil
 }
 
-func (s *Scanner) Name() string {
-	return "Сканнер"
+func (s *Scanner) ID() string {
+	return "scanner"
 }
 
-func (s *Scanner) RequiresTarget() bool {
-	return true
+func init() {
+	RegisterAbility(AbilityInfo{
+		ID:             "scanner",
+		Name:           i18n.M("ability.scanner"),
+		New:            func() Ability { return &Scanner{} },
+		Weight:         1,
+		RequiresTarget: true,
+		Aliases:        []string{"Сканнер"},
+	})
 }
 
-func (d *DoubleDamage) Apply(g *Game, target *Point) (*AbilityResult, error) {
+func (d *DoubleDamage) Apply(g *Game, target *AbilityTarget) (*AbilityResult, error) {
 	g.CurrentPlayer.HasDoubleDamage = true
-	return &AbilityResult{Message: "Следующая атака нанесет двойной урон!"}, nil
+	return &AbilityResult{Message: i18n.M("ability.double_damage.done")}, nil
 }
 
-func (s *DoubleDamage) Name() string {
-	return "Двойной урон"
+func (d *DoubleDamage) replay(g *Game, p *Player, target *AbilityTarget) {
+	p.HasDoubleDamage = true
 }
 
-func (d *DoubleDamage) RequiresTarget() bool {
-	return false
+func (d *DoubleDamage) ID() string {
+	return "double_damage"
 }
 
-func (p *Player) AddRandomAbility() {
-	abilities := []Ability{&ArtilleryStrike{}, &Scanner{}, &DoubleDamage{}}
-	rd := rand.Intn(len(abilities))
-	ability := abilities[rd]
-	p.Abilities = append(p.Abilities, ability)
+func init() {
+	RegisterAbility(AbilityInfo{
+		ID:      "double_damage",
+		Name:    i18n.M("ability.double_damage"),
+		New:     func() Ability { return &DoubleDamage{} },
+		Weight:  1,
+		Aliases: []string{"Двойной урон"},
+	})
+}
+
+// Apply пускает торпеду от крайней клетки target в направлении target.Direction, внутрь поля.
+// Клетки на пути, по которым еще не стреляли, обстреливаются по очереди; торпеда останавливается
+// на первом попадании в корабль или в щит, а пройденный путь возвращается в AffectedPoints
+func (t *Torpedo) Apply(g *Game, target *AbilityTarget) (*AbilityResult, error) {
+	if target == nil || target.Direction == "" {
+		return nil, fmt.Errorf("%w: для торпеды нужны крайняя клетка и направление", ErrNeedTarget)
+	}
+
+	enemyBoard := g.CurrentPlayer.EnemyBoard
+	if !enemyBoard.IsValidPoint(target.Point) {
+		return nil, fmt.Errorf("%w: (%d, %d)", ErrOutOfBounds, target.X, target.Y)
+	}
+	step, ok := torpedoStep(enemyBoard, target)
+	if !ok {
+		return nil, fmt.Errorf("%w: торпеду пускают с края поля внутрь, а (%d, %d) %s - нет", ErrBadTarget, target.X, target.Y, target.Direction)
+	}
+
+	var path []Point
+	for p := target.Point; enemyBoard.IsValidPoint(p); p.X, p.Y = p.X+step.X, p.Y+step.Y {
+		path = append(path, p)
+		if cell := enemyBoard.Grid[p.X][p.Y]; cell == MissCell || cell == HitCell {
+			continue
+		}
+
+		attack, err := g.attack(g.CurrentPlayer, p)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при пуске торпеды: %w", err)
+		}
+		if attack.Result == ResultMiss {
+			continue
+		}
+
+		msgID := "ability.torpedo.hit"
+		switch attack.Result {
+		case ResultSunk:
+			g.GrantRandomAbility(g.CurrentPlayer)
+		case ResultBlocked:
+			msgID = "ability.torpedo.blocked"
+		}
+		msg := i18n.Message{ID: msgID, Params: i18n.Params{"x": p.X, "y": p.Y}}
+		return &AbilityResult{Message: msg, AffectedPoints: path, AttackResults: []*AttackResultData{attack}}, nil
+	}
+
+	return &AbilityResult{Message: i18n.M("ability.torpedo.miss"), AffectedPoints: path}, nil
+}
+
+// torpedoStep - шаг торпеды; ok = false, если клетка не на том краю, с которого торпеда идет в этом направлении
+func torpedoStep(b *Board, target *AbilityTarget) (step Point, ok bool) {
+	switch target.Direction {
+	case DirDown:
+		return Point{X: 1}, target.X == 0
+	case DirUp:
+		return Point{X: -1}, target.X == len(b.Grid)-1
+	case DirRight:
+		return Point{Y: 1}, target.Y == 0
+	case DirLeft:
+		return Point{Y: -1}, target.Y == len(b.Grid[0])-1
+	}
+	return Point{}, false
+}
+
+func (t *Torpedo) ID() string {
+	return "torpedo"
+}
+
+func init() {
+	RegisterAbility(AbilityInfo{
+		ID:                "torpedo",
+		Name:              i18n.M("ability.torpedo"),
+		New:               func() Ability { return &Torpedo{} },
+		Weight:            1,
+		RequiresTarget:    true,
+		RequiresDirection: true,
+	})
+}
+
+// strikePattern обстреливает клетки фигуры pattern, заданной смещениями от target. Клетки за краем
+// поля и уже обстрелянные, в том числе открытые этим же ударом, пропускаются; способность не
+// срабатывает, только если в фигуре не осталось ни одной новой клетки
+func strikePattern(g *Game, id string, target *AbilityTarget, pattern []Point) (*AbilityResult, error) {
+	if target == nil {
+		return nil, fmt.Errorf("%w: для удара по площади необходимо указать координаты", ErrNeedTarget)
+	}
+
+	attacker := g.CurrentPlayer
+	enemyBoard := attacker.EnemyBoard
+	var affected []Point
+	var attacks []*AttackResultData
+	hits := 0
+	for _, offset := range pattern {
+		p := Point{X: target.X + offset.X, Y: target.Y + offset.Y}
+		if !enemyBoard.IsValidPoint(p) {
+			continue
+		}
+		affected = append(affected, p)
+		if cell := enemyBoard.Grid[p.X][p.Y]; cell == MissCell || cell == HitCell {
+			continue
+		}
+
+		attack, err := g.attack(attacker, p)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при ударе по площади: %w", err)
+		}
+		attacks = append(attacks, attack)
+
+		switch attack.Result {
+		case ResultSunk:
+			g.GrantRandomAbility(attacker)
+			hits++
+		case ResultHit:
+			hits++
+		}
+	}
+
+	if len(attacks) == 0 {
+		return nil, fmt.Errorf("%w: по всем клеткам вокруг (%d, %d) уже стреляли", ErrBadTarget, target.X, target.Y)
+	}
+
+	msg := i18n.Message{ID: "ability.strike.done", Params: i18n.Params{"name": AbilityName(id), "cells": len(attacks), "hits": hits}}
+	return &AbilityResult{
+		Message:        msg,
+		AffectedPoints: affected,
+		AttackResults:  attacks,
+	}, nil
+}
+
+// Apply бьет крестом: по цели и четырем соседним клеткам
+func (a *AirStrike) Apply(g *Game, target *AbilityTarget) (*AbilityResult, error) {
+	return strikePattern(g, a.ID(), target, []Point{{0, 0}, {-1, 0}, {1, 0}, {0, -1}, {0, 1}})
+}
+
+func (a *AirStrike) ID() string {
+	return "air_strike"
+}
+
+func init() {
+	RegisterAbility(AbilityInfo{
+		ID:             "air_strike",
+		Name:           i18n.M("ability.air_strike"),
+		New:            func() Ability { return &AirStrike{} },
+		Weight:         1,
+		RequiresTarget: true,
+	})
+}
+
+// Apply накрывает квадрат 2x2, цель - его левый верхний угол
+func (c *CarpetBomb) Apply(g *Game, target *AbilityTarget) (*AbilityResult, error) {
+	return strikePattern(g, c.ID(), target, []Point{{0, 0}, {0, 1}, {1, 0}, {1, 1}})
+}
+
+func (c *CarpetBomb) ID() string {
+	return "carpet_bomb"
+}
+
+func init() {
+	RegisterAbility(AbilityInfo{
+		ID:             "carpet_bomb",
+		Name:           i18n.M("ability.carpet_bomb"),
+		New:            func() Ability { return &CarpetBomb{} },
+		Weight:         1,
+		RequiresTarget: true,
+	})
+}
+
+// Apply бьет по диагонали из пяти клеток сверху слева вниз направо, цель - ее середина
+func (d *DiagonalStrike) Apply(g *Game, target *AbilityTarget) (*AbilityResult, error) {
+	return strikePattern(g, d.ID(), target, []Point{{-2, -2}, {-1, -1}, {0, 0}, {1, 1}, {2, 2}})
+}
+
+func (d *DiagonalStrike) ID() string {
+	return "diagonal_strike"
+}
+
+func init() {
+	RegisterAbility(AbilityInfo{
+		ID:             "diagonal_strike",
+		Name:           i18n.M("ability.diagonal_strike"),
+		New:            func() Ability { return &DiagonalStrike{} },
+		Weight:         1,
+		RequiresTarget: true,
+	})
+}
+
+// Apply ставит щит на целый сегмент своего корабля: следующее попадание в него будет отражено
+func (s *Shield) Apply(g *Game, target *AbilityTarget) (*AbilityResult, error) {
+	if target == nil {
+		return nil, fmt.Errorf("%w: для щита необходимо указать сегмент корабля", ErrNeedTarget)
+	}
+	if err := g.CurrentPlayer.MyBoard.shieldSegment(target.Point); err != nil {
+		return nil, err
+	}
+
+	msg := i18n.Message{ID: "ability.shield.done", Params: i18n.Params{"x": target.X, "y": target.Y}}
+	return &AbilityResult{Message: msg, AffectedPoints: []Point{target.Point}}, nil
+}
+
+func (s *Shield) replay(g *Game, p *Player, target *AbilityTarget) {
+	if target != nil {
+		p.MyBoard.shieldSegment(target.Point)
+	}
+}
+
+func (s *Shield) ID() string {
+	return "shield"
+}
+
+func init() {
+	RegisterAbility(AbilityInfo{
+		ID:             "shield",
+		Name:           i18n.M("ability.shield"),
+		New:            func() Ability { return &Shield{} },
+		Weight:         1,
+		RequiresTarget: true,
+		OwnBoard:       true,
+	})
+}
+
+// Apply ставит ложную цель в пустую клетку своего поля: выстрел в нее покажет попадание
+func (d *Decoy) Apply(g *Game, target *AbilityTarget) (*AbilityResult, error) {
+	if target == nil {
+		return nil, fmt.Errorf("%w: для ложной цели необходимо указать клетку", ErrNeedTarget)
+	}
+	if err := g.CurrentPlayer.MyBoard.placeDecoy(target.Point); err != nil {
+		return nil, err
+	}
+
+	msg := i18n.Message{ID: "ability.decoy.done", Params: i18n.Params{"x": target.X, "y": target.Y}}
+	return &AbilityResult{Message: msg, AffectedPoints: []Point{target.Point}}, nil
+}
+
+func (d *Decoy) replay(g *Game, p *Player, target *AbilityTarget) {
+	if target != nil {
+		p.MyBoard.placeDecoy(target.Point)
+	}
+}
+
+func (d *Decoy) ID() string {
+	return "decoy"
+}
+
+func init() {
+	RegisterAbility(AbilityInfo{
+		ID:             "decoy",
+		Name:           i18n.M("ability.decoy"),
+		New:            func() Ability { return &Decoy{} },
+		Weight:         1,
+		RequiresTarget: true,
+		OwnBoard:       true,
+	})
+}
+
+// Apply чинит подбитый сегмент своего непотопленного корабля. Бот противника забывает
+// об этом попадании, а человек снова видит клетку необстрелянной
+func (r *Repair) Apply(g *Game, target *AbilityTarget) (*AbilityResult, error) {
+	if target == nil {
+		return nil, fmt.Errorf("%w: для ремонта необходимо указать подбитый сегмент", ErrNeedTarget)
+	}
+	if err := g.CurrentPlayer.MyBoard.repairSegment(target.Point); err != nil {
+		return nil, err
+	}
+	forgetRepaired(g, g.CurrentPlayer, target.Point)
+
+	msg := i18n.Message{ID: "ability.repair.done", Params: i18n.Params{"x": target.X, "y": target.Y}}
+	return &AbilityResult{Message: msg, AffectedPoints: []Point{target.Point}}, nil
+}
+
+func (r *Repair) replay(g *Game, p *Player, target *AbilityTarget) {
+	if target == nil {
+		return
+	}
+	if p.MyBoard.repairSegment(target.Point) == nil {
+		forgetRepaired(g, p, target.Point)
+	}
+}
+
+// forgetRepaired - бот противника забывает попадание в клетку, которую owner отремонтировал
+func forgetRepaired(g *Game, owner *Player, p Point) {
+	if memory, ok := g.Opponent(owner).Strategy.(interface{ forget(Point) }); ok {
+		memory.forget(p)
+	}
+}
+
+func (r *Repair) ID() string {
+	return "repair"
+}
+
+func init() {
+	RegisterAbility(AbilityInfo{
+		ID:             "repair",
+		Name:           i18n.M("ability.repair"),
+		New:            func() Ability { return &Repair{} },
+		Weight:         1,
+		RequiresTarget: true,
+		OwnBoard:       true,
+	})
 }
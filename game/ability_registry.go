@@ -0,0 +1,119 @@
+package game
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sea_battle/i18n"
+	"slices"
+)
+
+// abilityRegistry - все способности в порядке регистрации. Порядок важен: по нему идет
+// случайная выдача, и партия с тем же зерном должна выдавать те же способности
+var abilityRegistry []AbilityInfo
+
+// RegisterAbility добавляет способность в реестр; вызывается из init рядом с ее типом
+func RegisterAbility(info AbilityInfo) {
+	if info.ID == "" || info.New == nil {
+		panic("способности нужны ID и конструктор")
+	}
+	if info.RequiresDirection && !info.RequiresTarget {
+		panic("направление задается от клетки-цели: " + info.ID)
+	}
+	if _, ok := LookupAbility(info.ID); ok {
+		panic("способность " + info.ID + " уже зарегистрирована")
+	}
+	if info.New().ID() != info.ID {
+		panic("ID типа способности не совпадает с записью реестра: " + info.ID)
+	}
+	abilityRegistry = append(abilityRegistry, info)
+}
+
+// Abilities возвращает все зарегистрированные способности
+func Abilities() []AbilityInfo {
+	return append([]AbilityInfo(nil), abilityRegistry...)
+}
+
+func LookupAbility(id string) (AbilityInfo, bool) {
+	for _, info := range abilityRegistry {
+		if info.ID == id {
+			return info, true
+		}
+	}
+	return AbilityInfo{}, false
+}
+
+// AbilityInfoOf - запись реестра для способности a
+func AbilityInfoOf(a Ability) AbilityInfo {
+	info, _ := LookupAbility(a.ID())
+	return info
+}
+
+// DTO - описание способности для клиента с названием на языке lang
+func (info AbilityInfo) DTO(lang i18n.Lang) AbilityDTO {
+	return AbilityDTO{
+		ID:                info.ID,
+		Name:              lang.Format(info.Name),
+		RequiresTarget:    info.RequiresTarget,
+		RequiresDirection: info.RequiresDirection,
+		OwnBoard:          info.OwnBoard,
+	}
+}
+
+// NewAbility создает способность по ID из сохранения, журнала или кода позиции
+func NewAbility(id string) (Ability, error) {
+	info, ok := LookupAbility(id)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownAbility, id)
+	}
+	return info.New(), nil
+}
+
+// AbilityName - название способности для игрока
+func AbilityName(id string) i18n.Message {
+	if info, ok := LookupAbility(id); ok {
+		return info.Name
+	}
+	return i18n.M("ability." + id)
+}
+
+// FindAbility ищет способность по ID или по названию на языке lang
+func FindAbility(name string, lang i18n.Lang) (AbilityInfo, bool) {
+	for _, info := range abilityRegistry {
+		if info.ID == name || lang.Format(info.Name) == name {
+			return info, true
+		}
+	}
+	return AbilityInfo{}, false
+}
+
+// legacyAbilityID - ID способности по названию из сохранения первой версии.
+// Самые старые файлы уже писали английские имена, совпадающие с ID
+func legacyAbilityID(name string) (string, error) {
+	for _, info := range abilityRegistry {
+		if info.ID == name || slices.Contains(info.Aliases, name) {
+			return info.ID, nil
+		}
+	}
+	return "", fmt.Errorf("%w %q", ErrUnknownAbility, name)
+}
+
+// randomAbility выбирает способность с учетом весов
+func randomAbility(rng *rand.Rand) Ability {
+	total := 0
+	for _, info := range abilityRegistry {
+		total += info.Weight
+	}
+
+	n := rng.IntN(total)
+	for _, info := range abilityRegistry {
+		if n < info.Weight {
+			return info.New()
+		}
+		n -= info.Weight
+	}
+	panic("в реестре нет способностей для случайной выдачи")
+}
+
+func (p *Player) AddRandomAbility(rng *rand.Rand) {
+	p.Abilities = append(p.Abilities, randomAbility(rng))
+}
@@ -0,0 +1,127 @@
+package game
+
+import "math/rand/v2"
+
+// densityTarget выбирает клетку, которую накрывает наибольшее число возможных расстановок оставшихся кораблей.
+// Если есть подбитый, но не потопленный корабль, учитываются только расстановки, проходящие через все его попадания,
+// поэтому после двух попаданий бот сам стреляет вдоль найденной линии корабля
+func (h *HardStrategy) densityTarget(rng *rand.Rand, enemy *Board) (Point, bool) {
+	blocked := make(map[Point]bool)
+	for _, p := range h.AllHits {
+		blocked[p] = true
+	}
+	for _, p := range h.VerifiedPoints {
+		blocked[p] = true
+	}
+	for _, p := range h.planned {
+		blocked[p] = true
+	}
+
+	active := make(map[Point]bool)
+	for _, p := range h.TargetHits {
+		active[p] = true
+		delete(blocked, p)
+	}
+
+	density := placementDensity(enemy, blocked, h.TargetHits, true)
+	if len(h.TargetHits) > 0 && len(density) == 0 {
+		// если кораблям можно касаться, попадания могут принадлежать разным кораблям
+		density = placementDensity(enemy, blocked, h.TargetHits, false)
+	}
+	if len(density) == 0 {
+		density = placementDensity(enemy, blocked, nil, false)
+	}
+
+	// клетки обходятся по порядку, а не по карте, чтобы при одном зерне бот стрелял одинаково
+	var best []Point
+	bestScore := 0
+	for x := range enemy.Grid {
+		for y := range enemy.Grid[x] {
+			p := Point{X: x, Y: y}
+			score := density[p]
+			if score == 0 || active[p] {
+				continue
+			}
+			switch {
+			case score > bestScore:
+				bestScore = score
+				best = []Point{p}
+			case score == bestScore:
+				best = append(best, p)
+			}
+		}
+	}
+
+	if len(best) == 0 {
+		return Point{}, false
+	}
+
+	return best[rng.IntN(len(best))], true
+}
+
+// placementDensity считает для каждой клетки число расстановок оставшихся кораблей, которые ее накрывают.
+// Учитываются только расстановки, проходящие через все (requireAll) или хотя бы одно из попаданий hits
+func placementDensity(enemy *Board, blocked map[Point]bool, hits []Point, requireAll bool) map[Point]int {
+	density := make(map[Point]int)
+
+	for _, ship := range enemy.Ships {
+		if ship.IsSunk {
+			continue
+		}
+
+		for x := range enemy.Grid {
+			for y := range enemy.Grid[x] {
+				for _, vertical := range []bool{false, true} {
+					if ship.Size == 1 && vertical {
+						continue
+					}
+
+					cells, ok := placementCells(enemy, Point{X: x, Y: y}, ship.Size, vertical, blocked)
+					if !ok || !covers(cells, hits, requireAll) {
+						continue
+					}
+					for _, c := range cells {
+						density[c]++
+					}
+				}
+			}
+		}
+	}
+
+	return density
+}
+
+func placementCells(enemy *Board, start Point, size int, vertical bool, blocked map[Point]bool) ([]Point, bool) {
+	cells := make([]Point, size)
+	for i := 0; i < size; i++ {
+		p := start
+		if vertical {
+			p.X += i
+		} else {
+			p.Y += i
+		}
+
+		if !enemy.IsValidPoint(p) || blocked[p] {
+			return nil, false
+		}
+		cells[i] = p
+	}
+	return cells, true
+}
+
+func covers(cells []Point, points []Point, requireAll bool) bool {
+	if len(points) == 0 {
+		return true
+	}
+
+	for _, p := range points {
+		found := contains(cells, p)
+		if requireAll && !found {
+			return false
+		}
+		if !requireAll && found {
+			return true
+		}
+	}
+	return requireAll
+}
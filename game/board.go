@@ -3,56 +3,95 @@ package game
 import (
 	"errors"
 	"fmt"
-	"math/rand"
+	"math/rand/v2"
 )
 
-func NewBoard() *Board {
+const placeBoardAttempts = 100
+
+func NewBoard(rules Rules) *Board {
 	return &Board{
-		Grid:  [10][10]CellState{},
+		Grid:  newGrid(rules.Width, rules.Height),
 		Ships: []Ship{},
+		Rules: rules,
+	}
+}
+
+func newGrid(width, height int) [][]CellState {
+	grid := make([][]CellState, height)
+	for i := range grid {
+		grid[i] = make([]CellState, width)
 	}
+	return grid
 }
 
-func NewBoardWithShips(shipsToPlace []Ship) (*Board, error) {
-	b := NewBoard()
-	for _, shipData := range shipsToPlace {
+// NewBoardWithShips расставляет присланный флот и проверяет его по правилам партии.
+// Все найденные нарушения возвращаются разом в *FleetError
+func NewBoardWithShips(rules Rules, shipsToPlace []Ship) (*Board, error) {
+	b := NewBoard(rules)
+	var problems []FleetProblem
+
+	longest := 0
+	for _, size := range rules.Fleet {
+		longest = max(longest, size)
+	}
+
+	for i, shipData := range shipsToPlace {
+		if shipData.Size < 1 || shipData.Size > longest {
+			problems = append(problems, newFleetProblem(i, shipData, ReasonInvalidSize))
+			continue
+		}
 		if len(shipData.Position) == 0 {
-			return nil, fmt.Errorf("не указана стартовая позиция для корабля размером %d", shipData.Size)
+			problems = append(problems, newFleetProblem(i, shipData, ReasonNoPosition))
+			continue
 		}
+
 		startPoint := shipData.Position[0]
 		s := Ship{
 			Size:       shipData.Size,
 			IsVertical: shipData.IsVertical,
 		}
 		if err := b.placeShip(&s, startPoint); err != nil {
-			return nil, fmt.Errorf("не удалось разместить %d-палубный корабль в (%d, %d): %w", s.Size, startPoint.X, startPoint.Y, err)
+			reason := ReasonTouching
+			switch {
+			case errors.Is(err, ErrOutOfBounds):
+				reason = ReasonOutOfBounds
+			case errors.Is(err, ErrShipsOverlap):
+				reason = ReasonOverlapping
+			}
+			problems = append(problems, newFleetProblem(i, shipData, reason))
 		}
 	}
+
+	problems = append(problems, fleetCountProblems(rules, shipsToPlace, longest)...)
+	if len(problems) > 0 {
+		return nil, &FleetError{Problems: problems}
+	}
 	return b, nil
 }
 
 func (b *Board) placeShip(ship *Ship, startPoint Point) error {
 	shipPoints := make([]Point, ship.Size)
 	for i := 0; i < ship.Size; i++ {
-		if ship.IsVertical {
-			shipPoints[i] = Point{X: startPoint.X + i, Y: startPoint.Y}
-		} else {
-			shipPoints[i] = Point{X: startPoint.X, Y: startPoint.Y + i}
-		}
+		shipPoints[i] = shipSegment(startPoint, i, ship.IsVertical)
 	}
 
 	for _, p := range shipPoints {
-		if p.X < 0 || p.X >= 10 || p.Y < 0 || p.Y >= 10 {
-			return errors.New("корабль выходит за пределы поля")
+		if !b.IsValidPoint(p) {
+			return ErrOutOfBounds
+		}
+
+		if b.Rules.AllowTouching {
+			if b.Grid[p.X][p.Y] == ShipCell {
+				return ErrShipsOverlap
+			}
+			continue
 		}
 
 		for dx := -1; dx <= 1; dx++ { // проверка 3x3 квадрата вокруг точки корабля
 			for dy := -1; dy <= 1; dy++ {
-				checkX, checkY := p.X+dx, p.Y+dy
-				if checkX >= 0 && checkX < 10 && checkY >= 0 && checkY < 10 {
-					if b.Grid[checkX][checkY] == ShipCell {
-						return errors.New("корабль соприкасается или пересекается с другим")
-					}
+				check := Point{X: p.X + dx, Y: p.Y + dy}
+				if b.IsValidPoint(check) && b.Grid[check.X][check.Y] == ShipCell {
+					return ErrShipsTouching
 				}
 			}
 		}
@@ -68,23 +107,39 @@ func (b *Board) placeShip(ship *Ship, startPoint Point) error {
 	return nil
 }
 
-func (b *Board) PlaceBoard() {
-	possibleShips := [10]int{4, 3, 3, 2, 2, 2, 1, 1, 1, 1}
-	for _, size := range possibleShips {
-		for {
+// PlaceBoard случайно расставляет флот по правилам доски. Если флот не удается уместить,
+// расстановка начинается заново, а после placeBoardAttempts неудач возвращается ошибка
+func (b *Board) PlaceBoard(rng *rand.Rand) error {
+	for attempt := 0; attempt < placeBoardAttempts; attempt++ {
+		if b.tryPlaceFleet(rng) {
+			return nil
+		}
+		b.Grid = newGrid(b.Rules.Width, b.Rules.Height)
+		b.Ships = []Ship{}
+	}
+	return errors.New("не удалось расставить флот на поле")
+}
+
+func (b *Board) tryPlaceFleet(rng *rand.Rand) bool {
+	cells := b.Rules.Width * b.Rules.Height
+	for _, size := range b.Rules.FleetSizes() {
+		placed := false
+		for try := 0; try < cells*4 && !placed; try++ {
 			ship := Ship{
 				Size:       size,
-				IsVertical: (rand.Intn(2) == 1),
+				IsVertical: (rng.IntN(2) == 1),
 			}
 
-			x := rand.Intn(10)
-			y := rand.Intn(10)
+			x := rng.IntN(b.Rules.Height)
+			y := rng.IntN(b.Rules.Width)
 			startPoint := Point{X: x, Y: y}
-			if b.placeShip(&ship, startPoint) == nil {
-				break
-			}
+			placed = b.placeShip(&ship, startPoint) == nil
+		}
+		if !placed {
+			return false
 		}
 	}
+	return true
 }
 
 func (b *Board) AllShipSunk() bool {
@@ -96,18 +151,25 @@ func (b *Board) AllShipSunk() bool {
 	return true
 }
 
+// markSunkShip отмечает промахами клетки вокруг потопленного корабля. Если кораблям
+// разрешено касаться, соседние клетки ничего не говорят о флоте и не отмечаются
 func (b *Board) markSunkShip(ship *Ship) []Point {
 	points := ship.Position
 	markedCells := []Point{}
+	if b.Rules.AllowTouching {
+		return append(markedCells, points...)
+	}
+
 	for _, p := range points {
 		for dx := -1; dx <= 1; dx++ {
 			for dy := -1; dy <= 1; dy++ {
-				checkX, checkY := p.X+dx, p.Y+dy
-				if checkX >= 0 && checkX < 10 && checkY >= 0 && checkY < 10 {
-					markedCells = append(markedCells, Point{X: checkX, Y: checkY})
+				check := Point{X: p.X + dx, Y: p.Y + dy}
+				if b.IsValidPoint(check) {
+					markedCells = append(markedCells, check)
 
-					if b.Grid[checkX][checkY] == EmptyCell {
-						b.Grid[checkX][checkY] = MissCell
+					if b.Grid[check.X][check.Y] == EmptyCell {
+						b.Grid[check.X][check.Y] = MissCell
+						b.Decoys, _ = removePoint(b.Decoys, check)
 					}
 				}
 			}
@@ -116,43 +178,67 @@ func (b *Board) markSunkShip(ship *Ship) []Point {
 	return markedCells
 }
 
-func (b *Board) Attack(p *Point, attacker *Player) (AttackResult, []Point, error) {
-	if p.X < 0 || p.X >= 10 || p.Y < 0 || p.Y >= 10 {
-		return ResultMiss, nil, errors.New("атака вне поля")
+func (b *Board) IsValidPoint(p Point) bool {
+	return p.X >= 0 && p.X < len(b.Grid) && p.Y >= 0 && p.Y < len(b.Grid[p.X])
+}
+
+func (b *Board) Attack(p *Point, attacker *Player) (*AttackResultData, error) {
+	if !b.IsValidPoint(*p) {
+		return nil, ErrOutOfBounds
 	}
 
 	currentSquare := b.Grid[p.X][p.Y]
 	if currentSquare == MissCell || currentSquare == HitCell {
-		return ResultMiss, nil, errors.New("по этой клетке уже стреляли")
+		return nil, ErrAlreadyShot
 	}
 
+	data := &AttackResultData{Target: *p, Result: ResultMiss}
+	if currentSquare == EmptyCell && contains(b.Decoys, *p) {
+		// ложная цель неотличима от попадания, поэтому и двойной урон на нее тратится
+		b.Grid[p.X][p.Y] = HitCell
+		data.Result = ResultHit
+		if attacker.HasDoubleDamage {
+			attacker.HasDoubleDamage = false
+			data.DoubleDamage = true
+		}
+		return data, nil
+	}
 	if currentSquare == EmptyCell {
+		// двойной урон не сгорает при промахе и переходит на следующий выстрел
 		b.Grid[p.X][p.Y] = MissCell
-		return ResultMiss, nil, nil
+		return data, nil
+	}
+
+	if shields, ok := removePoint(b.Shields, *p); ok {
+		b.Shields = shields
+		data.Result = ResultBlocked
+		return data, nil
 	}
 
 	b.Grid[p.X][p.Y] = HitCell
 	for i := range b.Ships {
 		ship := &b.Ships[i]
 
-		isTargetShip := false
+		targetSegment := -1
 		for j := 0; j < len(ship.Position); j++ {
 			if p.X == ship.Position[j].X && p.Y == ship.Position[j].Y {
-				isTargetShip = true
+				targetSegment = j
 				break
 			}
 		}
 
-		if isTargetShip {
+		if targetSegment != -1 {
 			ship.Hits++
 
 			if attacker.HasDoubleDamage {
-				// if ship.Hits < ship.Size {
-				// 	ship.Hits++
-				// } // изменить логику
 				attacker.HasDoubleDamage = false
+				data.DoubleDamage = true
+				if bonus, ok := b.hitNeighbourSegment(ship, targetSegment); ok {
+					data.BonusHit = &bonus
+				}
 			}
 
+			data.Result = ResultHit
 			if ship.Hits >= ship.Size {
 				ship.IsSunk = true
 				markedCells := b.markSunkShip(ship)
@@ -161,11 +247,89 @@ func (b *Board) Attack(p *Point, attacker *Player) (AttackResult, []Point, error
 					observer.ShipSunk(markedCells)
 				}
 
-				return ResultSunk, markedCells, nil
+				data.Result = ResultSunk
+				data.MarkedPoints = markedCells
+			}
+			return data, nil
+		}
+	}
+
+	return nil, errors.New("ошибка состояния: клетка корабля есть, а самого корабля нет")
+}
+
+// hitNeighbourSegment подбивает соседний целый сегмент того же корабля - второй удар двойного урона
+func (b *Board) hitNeighbourSegment(ship *Ship, segment int) (Point, bool) {
+	for _, j := range []int{segment + 1, segment - 1} {
+		if j < 0 || j >= len(ship.Position) {
+			continue
+		}
+
+		p := ship.Position[j]
+		if shields, ok := removePoint(b.Shields, p); ok {
+			b.Shields = shields
+			return Point{}, false
+		}
+		if b.Grid[p.X][p.Y] == ShipCell {
+			b.Grid[p.X][p.Y] = HitCell
+			ship.Hits++
+			return p, true
+		}
+	}
+	return Point{}, false
+}
+
+// shieldSegment ставит щит на целый сегмент корабля
+func (b *Board) shieldSegment(p Point) error {
+	if !b.IsValidPoint(p) {
+		return fmt.Errorf("%w: (%d, %d)", ErrOutOfBounds, p.X, p.Y)
+	}
+	if b.Grid[p.X][p.Y] != ShipCell {
+		return fmt.Errorf("%w: щит ставится на целый сегмент своего корабля", ErrBadTarget)
+	}
+	if contains(b.Shields, p) {
+		return fmt.Errorf("%w: сегмент уже под щитом", ErrBadTarget)
+	}
+	b.Shields = append(b.Shields, p)
+	return nil
+}
+
+// placeDecoy ставит ложную цель в пустую клетку, по которой еще не стреляли
+func (b *Board) placeDecoy(p Point) error {
+	if !b.IsValidPoint(p) {
+		return fmt.Errorf("%w: (%d, %d)", ErrOutOfBounds, p.X, p.Y)
+	}
+	if b.Grid[p.X][p.Y] != EmptyCell || contains(b.Decoys, p) {
+		return fmt.Errorf("%w: ложная цель ставится в пустую необстрелянную клетку", ErrBadTarget)
+	}
+	b.Decoys = append(b.Decoys, p)
+	return nil
+}
+
+// repairSegment восстанавливает подбитый сегмент корабля, который еще не потоплен
+func (b *Board) repairSegment(p Point) error {
+	if !b.IsValidPoint(p) {
+		return fmt.Errorf("%w: (%d, %d)", ErrOutOfBounds, p.X, p.Y)
+	}
+	if b.Grid[p.X][p.Y] == HitCell {
+		for i := range b.Ships {
+			ship := &b.Ships[i]
+			if ship.IsSunk || !contains(ship.Position, p) {
+				continue
 			}
-			return ResultHit, nil, nil
+			b.Grid[p.X][p.Y] = ShipCell
+			ship.Hits--
+			return nil
 		}
 	}
+	return fmt.Errorf("%w: ремонтируется подбитый сегмент непотопленного корабля", ErrBadTarget)
+}
 
-	return ResultMiss, nil, errors.New("ошибка состояния: клетка корабля есть, а самого корабля нет")
+// removePoint убирает p из points; ok = false, если p там не было
+func removePoint(points []Point, p Point) ([]Point, bool) {
+	for i, pt := range points {
+		if pt == p {
+			return append(points[:i], points[i+1:]...), true
+		}
+	}
+	return points, false
 }
@@ -0,0 +1,406 @@
+package game
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Компактная запись позиции для баг-репортов: упакованный двоичный формат и его base64url-строка.
+//
+//	заголовок: "SB", вид ('b' - доска, 'g' - партия), версия формата
+//	доска:     ширина, высота, флаги (касание, залпы), сетка по 2 бита на клетку, число кораблей
+//	           и для каждого корабля X, Y начала и размер<<1 | вертикальность, затем с версии 2
+//	           число щитов и их X, Y и так же ложные цели
+//	партия:    флаги, победитель (0 - нет, 1 или 2), зерно, затем оба игрока:
+//	           имя, сложность бота (пусто у человека), ID способностей и доска
+//
+// Попадания и потопление кораблей восстанавливаются по сетке, память бота - по полю
+// противника. Журнал партии и состояние генератора в код не попадают, поэтому
+// загруженная по коду партия продолжается с зерна, а не с того же места генератора
+const compactVersion = 2
+
+const (
+	compactKindBoard = 'b'
+	compactKindGame  = 'g'
+)
+
+const (
+	flagTouching = 1 << iota
+	flagSecondPlayerTurn
+	flagFirstDoubleDamage
+	flagSecondDoubleDamage
+	flagSalvo
+)
+
+var compactEncoding = base64.RawURLEncoding
+
+func compactHeader(kind byte) []byte {
+	return []byte{'S', 'B', kind, compactVersion}
+}
+
+func (b *Board) MarshalBinary() ([]byte, error) {
+	return b.appendBinary(compactHeader(compactKindBoard))
+}
+
+func (b *Board) UnmarshalBinary(data []byte) error {
+	r := &codeReader{data: data}
+	r.header(compactKindBoard)
+	decoded, rules := r.board()
+	if r.err != nil {
+		return r.err
+	}
+	if err := rules.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrBadCode, err)
+	}
+	if problems := decoded.validate(rules); len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrBadCode, problems[0])
+	}
+	*b = *decoded
+	return nil
+}
+
+// EncodeBoardCode возвращает доску в виде короткой строки, пригодной для URL
+func EncodeBoardCode(b *Board) (string, error) {
+	data, err := b.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return compactEncoding.EncodeToString(data), nil
+}
+
+func DecodeBoardCode(code string) (*Board, error) {
+	data, err := compactEncoding.DecodeString(code)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadCode, err)
+	}
+	var b Board
+	if err := b.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (b *Board) appendBinary(out []byte) ([]byte, error) {
+	width, height := len(b.Grid[0]), len(b.Grid)
+	if width > 255 || height > 255 {
+		return nil, errors.New("поле слишком велико для компактной записи")
+	}
+
+	var flags byte
+	if b.Rules.AllowTouching {
+		flags |= flagTouching
+	}
+	if b.Rules.Mode == ModeSalvo {
+		flags |= flagSalvo
+	}
+	out = append(out, byte(width), byte(height), flags)
+
+	packed := make([]byte, (width*height+3)/4)
+	for x, row := range b.Grid {
+		for y, cell := range row {
+			i := x*width + y
+			packed[i/4] |= byte(cell&3) << (2 * (i % 4))
+		}
+	}
+	out = append(out, packed...)
+
+	out = binary.AppendUvarint(out, uint64(len(b.Ships)))
+	for _, ship := range b.Ships {
+		if len(ship.Position) == 0 {
+			return nil, errors.New("у корабля нет позиции")
+		}
+		start := ship.Position[0]
+		sizeAndDir := byte(ship.Size << 1)
+		if ship.IsVertical {
+			sizeAndDir |= 1
+		}
+		out = append(out, byte(start.X), byte(start.Y), sizeAndDir)
+	}
+
+	for _, points := range [][]Point{b.Shields, b.Decoys} {
+		out = binary.AppendUvarint(out, uint64(len(points)))
+		for _, p := range points {
+			out = append(out, byte(p.X), byte(p.Y))
+		}
+	}
+	return out, nil
+}
+
+func (g *Game) MarshalBinary() ([]byte, error) {
+	out := compactHeader(compactKindGame)
+
+	var flags byte
+	if g.Rules.AllowTouching {
+		flags |= flagTouching
+	}
+	if g.CurrentPlayer == g.Player2 {
+		flags |= flagSecondPlayerTurn
+	}
+	if g.Player1.HasDoubleDamage {
+		flags |= flagFirstDoubleDamage
+	}
+	if g.Player2.HasDoubleDamage {
+		flags |= flagSecondDoubleDamage
+	}
+
+	var winner byte
+	switch g.Winner {
+	case "":
+	case g.Player1.Name:
+		winner = 1
+	case g.Player2.Name:
+		winner = 2
+	}
+	out = append(out, flags, winner)
+	out = binary.AppendUvarint(out, g.Seed)
+
+	for _, p := range []*Player{g.Player1, g.Player2} {
+		out = appendString(out, p.Name)
+
+		var level Difficulty
+		if p.Strategy != nil {
+			level = p.Strategy.Difficulty()
+		}
+		out = appendString(out, string(level))
+
+		out = binary.AppendUvarint(out, uint64(len(p.Abilities)))
+		for _, ability := range p.Abilities {
+			out = appendString(out, ability.ID())
+		}
+
+		var err error
+		if out, err = p.MyBoard.appendBinary(out); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (g *Game) UnmarshalBinary(data []byte) error {
+	r := &codeReader{data: data}
+	r.header(compactKindGame)
+	flags, winner := r.byte(), r.byte()
+	seed := r.uvarint()
+
+	decoded := &Game{}
+	players := [2]*Player{}
+	var rules Rules
+	for i := range players {
+		p := &Player{Name: r.string(), Abilities: []Ability{}}
+		level := Difficulty(r.string())
+
+		for n := r.uvarint(); n > 0 && r.err == nil; n-- {
+			id := r.string()
+			ability, err := NewAbility(id)
+			if err != nil && r.err == nil {
+				r.err = err
+			}
+			p.Abilities = append(p.Abilities, ability)
+		}
+
+		p.MyBoard, rules = r.board()
+		if level != "" && r.err == nil {
+			if _, err := ParseDifficulty(string(level)); err != nil {
+				r.err = err
+			}
+			p.Strategy = NewStrategy(level)
+		}
+		players[i] = p
+	}
+	if r.err != nil {
+		return r.err
+	}
+	if len(r.data) != r.pos {
+		return fmt.Errorf("%w: лишние данные в конце", ErrBadCode)
+	}
+
+	decoded.Rules = rules
+	decoded.Player1, decoded.Player2 = players[0], players[1]
+	decoded.Player1.HasDoubleDamage = flags&flagFirstDoubleDamage != 0
+	decoded.Player2.HasDoubleDamage = flags&flagSecondDoubleDamage != 0
+	decoded.CurrentPlayer = decoded.Player1
+	if flags&flagSecondPlayerTurn != 0 {
+		decoded.CurrentPlayer = decoded.Player2
+	}
+	switch winner {
+	case 1:
+		decoded.Winner = decoded.Player1.Name
+	case 2:
+		decoded.Winner = decoded.Player2.Name
+	}
+
+	if err := decoded.Validate(); err != nil {
+		return err
+	}
+	decoded.Player1.EnemyBoard = decoded.Player2.MyBoard
+	decoded.Player2.EnemyBoard = decoded.Player1.MyBoard
+	for _, p := range players {
+		if p.Strategy != nil {
+			p.Strategy = restoreStrategy(p.Strategy.Difficulty(), p.EnemyBoard)
+		}
+	}
+	decoded.seedRandom(seed)
+
+	*g = *decoded
+	return nil
+}
+
+// EncodeGameCode возвращает партию в виде короткой строки для баг-репортов.
+// Код содержит расстановку обоих флотов
+func EncodeGameCode(g *Game) (string, error) {
+	data, err := g.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return compactEncoding.EncodeToString(data), nil
+}
+
+func DecodeGameCode(code string) (*Game, error) {
+	data, err := compactEncoding.DecodeString(code)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadCode, err)
+	}
+	var g Game
+	if err := g.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+func appendString(out []byte, s string) []byte {
+	out = binary.AppendUvarint(out, uint64(len(s)))
+	return append(out, s...)
+}
+
+// codeReader читает компактную запись; первая ошибка запоминается, а дальнейшие чтения возвращают нули
+type codeReader struct {
+	data    []byte
+	pos     int
+	err     error
+	version byte
+}
+
+func (r *codeReader) fail(what string) {
+	if r.err == nil {
+		r.err = fmt.Errorf("%w: %s", ErrBadCode, what)
+	}
+}
+
+func (r *codeReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.pos+n > len(r.data) {
+		r.fail("запись обрывается")
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *codeReader) byte() byte {
+	if b := r.bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *codeReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		r.fail("неверное число")
+		return 0
+	}
+	r.pos += n
+	return v
+}
+
+func (r *codeReader) string() string {
+	n := r.uvarint()
+	if n > uint64(len(r.data)) {
+		r.fail("слишком длинная строка")
+		return ""
+	}
+	return string(r.bytes(int(n)))
+}
+
+func (r *codeReader) header(kind byte) {
+	h := r.bytes(4)
+	if h == nil || h[0] != 'S' || h[1] != 'B' || h[2] != kind {
+		r.fail("не тот вид записи")
+		return
+	}
+	r.version = h[3]
+	if r.version < 1 || r.version > compactVersion {
+		r.fail(fmt.Sprintf("неизвестная версия формата %d", h[3]))
+	}
+}
+
+// board читает доску и правила, которые по ней восстанавливаются: флот - это размеры ее кораблей
+func (r *codeReader) board() (*Board, Rules) {
+	width, height, flags := int(r.byte()), int(r.byte()), r.byte()
+	if r.err == nil && (width == 0 || height == 0) {
+		r.fail("пустое поле")
+	}
+	rules := Rules{Width: width, Height: height, AllowTouching: flags&flagTouching != 0}
+	if flags&flagSalvo != 0 {
+		rules.Mode = ModeSalvo
+	}
+	if r.err != nil {
+		return nil, rules
+	}
+
+	b := NewBoard(rules)
+	packed := r.bytes((width*height + 3) / 4)
+	for i := 0; i < width*height && packed != nil; i++ {
+		b.Grid[i/width][i%width] = CellState(packed[i/4] >> (2 * (i % 4)) & 3)
+	}
+
+	count := r.uvarint()
+	if count > uint64(width*height) {
+		r.fail("слишком много кораблей")
+	}
+	for n := uint64(0); n < count && r.err == nil; n++ {
+		x, y, sizeAndDir := int(r.byte()), int(r.byte()), r.byte()
+		ship := Ship{Size: int(sizeAndDir >> 1), IsVertical: sizeAndDir&1 != 0}
+		for i := 0; i < ship.Size; i++ {
+			p := shipSegment(Point{X: x, Y: y}, i, ship.IsVertical)
+			ship.Position = append(ship.Position, p)
+			if b.IsValidPoint(p) && b.Grid[p.X][p.Y] == HitCell {
+				ship.Hits++
+			}
+		}
+		ship.IsSunk = ship.Size > 0 && ship.Hits == ship.Size
+		b.Ships = append(b.Ships, ship)
+		rules.Fleet = append(rules.Fleet, ship.Size)
+	}
+
+	if r.version >= 2 {
+		b.Shields = r.points(width * height)
+		b.Decoys = r.points(width * height)
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(rules.Fleet)))
+	b.Rules = rules
+	return b, rules
+}
+
+// points читает список клеток: число, затем X и Y каждой
+func (r *codeReader) points(limit int) []Point {
+	count := r.uvarint()
+	if count > uint64(limit) {
+		r.fail("слишком много клеток")
+	}
+	var points []Point
+	for n := uint64(0); n < count && r.err == nil; n++ {
+		points = append(points, Point{X: int(r.byte()), Y: int(r.byte())})
+	}
+	return points
+}
@@ -0,0 +1,64 @@
+package game
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// playedGame возвращает партию после нескольких ходов человека и бота
+func playedGame(t *testing.T) *Game {
+	t.Helper()
+	g, err := NewGame(Options{Rules: DefaultRules(), Difficulty: Medium, Seed: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for x := 0; x < 5 && g.Winner == ""; x++ {
+		if g.CurrentPlayer == g.Player2 {
+			if _, err := g.HandleComputerTurn(); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if _, _, err := g.HandleHumanTurn(x, x); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return g
+}
+
+func TestGameCodeRoundTrip(t *testing.T) {
+	g := playedGame(t)
+	code, err := EncodeGameCode(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := DecodeGameCode(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Seed != g.Seed || loaded.Winner != g.Winner || loaded.CurrentPlayer.Name != g.CurrentPlayer.Name {
+		t.Errorf("после кода партия другая: зерно %d, победитель %q, ходит %q",
+			loaded.Seed, loaded.Winner, loaded.CurrentPlayer.Name)
+	}
+	for i, pair := range [][2]*Player{{g.Player1, loaded.Player1}, {g.Player2, loaded.Player2}} {
+		if !reflect.DeepEqual(pair[0].MyBoard.Grid, pair[1].MyBoard.Grid) {
+			t.Errorf("игрок %d: сетка доски не совпадает", i+1)
+		}
+		if !reflect.DeepEqual(pair[0].MyBoard.Ships, pair[1].MyBoard.Ships) {
+			t.Errorf("игрок %d: корабли не совпадают", i+1)
+		}
+	}
+}
+
+func TestDecodeGameCodeRejectsGarbage(t *testing.T) {
+	for _, code := range []string{"", "не base64", "U0Jn"} {
+		if _, err := DecodeGameCode(code); err == nil {
+			t.Errorf("код %q принят", code)
+		}
+	}
+	if _, err := DecodeGameCode("%%%"); !errors.Is(err, ErrBadCode) {
+		t.Errorf("DecodeGameCode вернул %v, ожидалась ErrBadCode", err)
+	}
+}
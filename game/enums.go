@@ -0,0 +1,106 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// В JSON состояния клеток и результаты выстрелов пишутся строками. Числа по-прежнему
+// принимаются при чтении, чтобы загружались сохранения, сделанные до перехода на строки
+
+var cellStateNames = []string{"empty", "ship", "miss", "hit"}
+
+var attackResultNames = []string{"miss", "hit", "sunk", "blocked"}
+
+func enumName(names []string, v int) string {
+	if v >= 0 && v < len(names) {
+		return names[v]
+	}
+	return fmt.Sprintf("unknown(%d)", v)
+}
+
+// unmarshalEnum разбирает строковое имя или, для старых сохранений, число
+func unmarshalEnum(names []string, data []byte, kind string) (int, error) {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		var n int
+		if err := json.Unmarshal(data, &n); err != nil || n < 0 || n >= len(names) {
+			return 0, fmt.Errorf("неверное значение %s: %s", kind, data)
+		}
+		return n, nil
+	}
+
+	for i, candidate := range names {
+		if candidate == name {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("неизвестное значение %s: %q", kind, name)
+}
+
+func (c CellState) String() string {
+	return enumName(cellStateNames, int(c))
+}
+
+func (c CellState) EnumValues() []string {
+	return cellStateNames
+}
+
+func (c CellState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.String())
+}
+
+func (c *CellState) UnmarshalJSON(data []byte) error {
+	n, err := unmarshalEnum(cellStateNames, data, "клетки")
+	*c = CellState(n)
+	return err
+}
+
+func (r AttackResult) String() string {
+	return enumName(attackResultNames, int(r))
+}
+
+// KeepsTurn - дает ли выстрел право стрелять еще раз: промах и выстрел в щит передают ход
+func (r AttackResult) KeepsTurn() bool {
+	return r == ResultHit || r == ResultSunk
+}
+
+func (r AttackResult) EnumValues() []string {
+	return attackResultNames
+}
+
+func (r AttackResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.String())
+}
+
+func (r *AttackResult) UnmarshalJSON(data []byte) error {
+	n, err := unmarshalEnum(attackResultNames, data, "результата выстрела")
+	*r = AttackResult(n)
+	return err
+}
+
+func (d Difficulty) EnumValues() []string {
+	return []string{string(Easy), string(Medium), string(Hard)}
+}
+
+func (d Direction) EnumValues() []string {
+	return []string{string(DirUp), string(DirDown), string(DirLeft), string(DirRight)}
+}
+
+func (m GameMode) EnumValues() []string {
+	return []string{string(ModeClassic), string(ModeSalvo)}
+}
+
+func (t EventType) EnumValues() []string {
+	return []string{
+		string(EventPlacement), string(EventAttack), string(EventAbilityGrant),
+		string(EventAbilityUse), string(EventTurnSwitch), string(EventGameOver),
+	}
+}
+
+func (r FleetProblemReason) EnumValues() []string {
+	return []string{
+		string(ReasonInvalidSize), string(ReasonNoPosition), string(ReasonOutOfBounds),
+		string(ReasonTouching), string(ReasonOverlapping), string(ReasonExtraShip), string(ReasonMissingShip),
+	}
+}
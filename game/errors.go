@@ -0,0 +1,41 @@
+package game
+
+import "errors"
+
+// Ошибки пакета. Текст предназначен для человека и может уточняться обертками
+// fmt.Errorf("%w: ..."), поэтому сравнивать ошибки нужно через errors.Is
+var (
+	// ход
+	ErrGameOver      = errors.New("игра окончена")
+	ErrNotYourTurn   = errors.New("сейчас не ваш ход")
+	ErrNotBotTurn    = errors.New("текущим игроком управляет человек")
+	ErrOutOfBounds   = errors.New("выход за пределы поля")
+	ErrAlreadyShot   = errors.New("по этой клетке уже стреляли")
+	ErrNoSuchAbility = errors.New("у вас нет такой способности или она не существует")
+	ErrNeedTarget    = errors.New("способности нужна цель")
+	ErrBadTarget     = errors.New("недопустимая цель способности")
+	ErrWrongMode     = errors.New("ход не подходит к режиму партии")
+	ErrShotCount     = errors.New("неверное число выстрелов в залпе")
+	ErrNoTargets     = errors.New("не осталось клеток, по которым можно стрелять")
+
+	// расстановка и правила
+	ErrShipsTouching     = errors.New("корабль соприкасается с другим")
+	ErrShipsOverlap      = errors.New("корабль пересекается с другим")
+	ErrInvalidFleet      = errors.New("флот не соответствует правилам") // *FleetError со списком нарушений
+	ErrInvalidRules      = errors.New("недопустимые правила")
+	ErrUnknownDifficulty = errors.New("неизвестный уровень сложности")
+
+	// журнал
+	ErrNoHistory   = errors.New("в этой партии не сохранен журнал ходов")
+	ErrInvalidStep = errors.New("неверный номер хода")
+
+	// сохранения и коды позиций
+	ErrSaveNotFound       = errors.New("слот сохранения не найден") // возвращают хранилища, если в слоте ничего не сохранено
+	ErrInvalidSlot        = errors.New("недопустимое имя слота")
+	ErrCorruptSave        = errors.New("сохранение повреждено")
+	ErrUnknownSaveVersion = errors.New("неизвестная версия сохранения")
+	ErrBadSignature       = errors.New("подпись сохранения не прошла проверку")
+	ErrUnknownAbility     = errors.New("неизвестная способность")
+	ErrInvalidGame        = errors.New("партия не прошла проверку")
+	ErrBadCode            = errors.New("неверный код позиции")
+)
@@ -0,0 +1,105 @@
+package game
+
+import (
+	"sea_battle/i18n"
+	"strings"
+)
+
+type FleetProblemReason string
+
+const (
+	ReasonInvalidSize FleetProblemReason = "invalid_size"
+	ReasonNoPosition  FleetProblemReason = "no_position"
+	ReasonOutOfBounds FleetProblemReason = "out_of_bounds"
+	ReasonTouching    FleetProblemReason = "touching"
+	ReasonOverlapping FleetProblemReason = "overlapping"
+	ReasonExtraShip   FleetProblemReason = "extra_ship"
+	ReasonMissingShip FleetProblemReason = "missing_ship"
+)
+
+// FleetProblem - нарушение правил одним кораблем присланного флота
+type FleetProblem struct {
+	Index   int                `json:"index"` // номер корабля в запросе, -1 для недостающих кораблей
+	Size    int                `json:"size"`
+	Start   *Point             `json:"start,omitempty"`
+	Reason  FleetProblemReason `json:"reason"`
+	Message string             `json:"message"` // текст Text на языке по умолчанию, сервер переводит его под клиента
+	Text    i18n.Message       `json:"-"`
+}
+
+type FleetError struct {
+	Problems []FleetProblem `json:"problems"`
+}
+
+func (e *FleetError) Error() string {
+	messages := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		messages[i] = problem.Message
+	}
+	return ErrInvalidFleet.Error() + ": " + strings.Join(messages, "; ")
+}
+
+// Is позволяет проверять ошибку расстановки через errors.Is: она совпадает с ErrInvalidFleet,
+// а также с ErrOutOfBounds, ErrShipsTouching и ErrShipsOverlap, если среди нарушений есть такое
+func (e *FleetError) Is(target error) bool {
+	if target == ErrInvalidFleet {
+		return true
+	}
+	for _, problem := range e.Problems {
+		switch {
+		case problem.Reason == ReasonOutOfBounds && target == ErrOutOfBounds,
+			problem.Reason == ReasonTouching && target == ErrShipsTouching,
+			problem.Reason == ReasonOverlapping && target == ErrShipsOverlap:
+			return true
+		}
+	}
+	return false
+}
+
+func newFleetProblem(index int, ship Ship, reason FleetProblemReason) FleetProblem {
+	problem := FleetProblem{Index: index, Size: ship.Size, Reason: reason}
+	if len(ship.Position) > 0 {
+		start := ship.Position[0]
+		problem.Start = &start
+	}
+
+	problem.Text = problem.message()
+	problem.Message = i18n.Default.Format(problem.Text)
+	return problem
+}
+
+func (p FleetProblem) message() i18n.Message {
+	params := i18n.Params{"n": p.Index + 1, "size": p.Size}
+	if p.Start != nil {
+		params["x"], params["y"] = p.Start.X, p.Start.Y
+	}
+	return i18n.Message{ID: "fleet." + string(p.Reason), Params: params}
+}
+
+// fleetCountProblems сравнивает число кораблей каждого размера с составом флота из правил
+func fleetCountProblems(rules Rules, ships []Ship, longest int) []FleetProblem {
+	expected := make(map[int]int)
+	for _, size := range rules.Fleet {
+		expected[size]++
+	}
+
+	var problems []FleetProblem
+	seen := make(map[int]int)
+	for i, ship := range ships {
+		if ship.Size < 1 || ship.Size > longest {
+			continue
+		}
+		seen[ship.Size]++
+		if seen[ship.Size] > expected[ship.Size] {
+			problems = append(problems, newFleetProblem(i, ship, ReasonExtraShip))
+		}
+	}
+
+	for _, size := range rules.FleetSizes() {
+		if seen[size] < expected[size] {
+			problems = append(problems, newFleetProblem(-1, Ship{Size: size}, ReasonMissingShip))
+			seen[size]++
+		}
+	}
+	return problems
+}
@@ -2,10 +2,17 @@ package game
 
 import (
 	"fmt"
+	"os"
 )
 
-func NewGameFromFile(filename string) *Game {
-	game, err := LoadGame(filename)
+func NewGameFromFile(filename string, key []byte) *Game {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Println("Ошибка при попытке загрузить файл для начала игры:", err)
+		return nil
+	}
+
+	game, err := DecodeGame(data, key)
 	if err != nil {
 		fmt.Println("Ошибка при попытке загрузить файл для начала игры:", err)
 		return nil
@@ -13,44 +20,42 @@ func NewGameFromFile(filename string) *Game {
 	return game
 }
 
-func NewGame() *Game {
-	playerBoard := NewBoard()
-	computerBoard := NewBoard()
-	playerBoard.PlaceBoard()
-	computerBoard.PlaceBoard()
+func NewGame(opts Options) (*Game, error) {
+	if err := opts.Rules.Validate(); err != nil {
+		return nil, err
+	}
 
-	p1 := Player{
-		Name:            "Player",
-		MyBoard:         playerBoard,
-		EnemyBoard:      computerBoard,
-		Abilities:       []Ability{},
-		HasDoubleDamage: false,
+	game := &Game{Rules: opts.Rules}
+	game.seedRandom(opts.Seed)
+
+	playerBoard := NewBoard(opts.Rules)
+	if err := playerBoard.PlaceBoard(game.Rand()); err != nil {
+		return nil, err
 	}
 
-	p2 := Player{
-		Name:            "Computer",
-		MyBoard:         computerBoard,
-		EnemyBoard:      playerBoard,
-		Abilities:       []Ability{},
-		HasDoubleDamage: false,
-		State:           Searching,
-		AllHits:         []Point{},
-		TargetHits:      []Point{},
-		VerifiedPoints:  []Point{},
+	return game.startVsComputer(playerBoard, opts.Difficulty)
+}
+
+func NewGameManual(opts Options, playerBoard *Board) (*Game, error) {
+	if err := opts.Rules.Validate(); err != nil {
+		return nil, err
 	}
 
-	game := Game{
-		Player1:       &p1,
-		Player2:       &p2,
-		CurrentPlayer: &p1,
+	if len(playerBoard.Grid) != opts.Rules.Height || len(playerBoard.Grid[0]) != opts.Rules.Width {
+		return nil, fmt.Errorf("%w: размер поля игрока не совпадает с правилами партии", ErrInvalidFleet)
 	}
 
-	return &game
+	game := &Game{Rules: opts.Rules}
+	game.seedRandom(opts.Seed)
+	return game.startVsComputer(playerBoard, opts.Difficulty)
 }
 
-func NewGameManual(playerBoard *Board) *Game {
-	computerBoard := NewBoard()
-	computerBoard.PlaceBoard()
+// startVsComputer расставляет флот бота и начинает партию против него
+func (g *Game) startVsComputer(playerBoard *Board, level Difficulty) (*Game, error) {
+	computerBoard := NewBoard(g.Rules)
+	if err := computerBoard.PlaceBoard(g.Rand()); err != nil {
+		return nil, err
+	}
 
 	p1 := Player{
 		Name:            "Player",
@@ -66,19 +71,16 @@ func NewGameManual(playerBoard *Board) *Game {
 		EnemyBoard:      playerBoard,
 		Abilities:       []Ability{},
 		HasDoubleDamage: false,
-		State:           Searching,
-		AllHits:         []Point{},
-		TargetHits:      []Point{},
-		VerifiedPoints:  []Point{},
+		Strategy:        NewStrategy(level),
 	}
 
-	game := Game{
-		Player1:       &p1,
-		Player2:       &p2,
-		CurrentPlayer: &p1,
-	}
+	g.Player1 = &p1
+	g.Player2 = &p2
+	g.CurrentPlayer = &p1
+	g.recordPlacement(g.Player1)
+	g.recordPlacement(g.Player2)
 
-	return &game
+	return g, nil
 }
 
 func (g *Game) SwitchPlayer() {
@@ -87,4 +89,34 @@ func (g *Game) SwitchPlayer() {
 	} else {
 		g.CurrentPlayer = g.Player1
 	}
+	g.record(Event{Type: EventTurnSwitch, Player: g.CurrentPlayer.Name})
+}
+
+// NewGameVersus создает партию двух людей с заранее расставленными флотами
+func NewGameVersus(opts Options, name1 string, board1 *Board, name2 string, board2 *Board) *Game {
+	p1 := Player{
+		Name:       name1,
+		MyBoard:    board1,
+		EnemyBoard: board2,
+		Abilities:  []Ability{},
+	}
+
+	p2 := Player{
+		Name:       name2,
+		MyBoard:    board2,
+		EnemyBoard: board1,
+		Abilities:  []Ability{},
+	}
+
+	game := Game{
+		Rules:         opts.Rules,
+		Player1:       &p1,
+		Player2:       &p2,
+		CurrentPlayer: &p1,
+	}
+	game.seedRandom(opts.Seed)
+	game.recordPlacement(game.Player1)
+	game.recordPlacement(game.Player2)
+
+	return &game
 }
@@ -2,29 +2,41 @@ package game
 
 import (
 	"fmt"
-	"math/rand"
+	"sea_battle/i18n"
 )
 
-func (g *Game) HandleHumanTurn(x, y int) (AttackResult, []Point, string, error) {
-	attackPoint := Point{X: x, Y: y}
-	result, markedPoints, err := g.CurrentPlayer.EnemyBoard.Attack(&attackPoint, g.CurrentPlayer)
+func (g *Game) HandleHumanTurn(x, y int) (*AttackResultData, i18n.Message, error) {
+	if g.IsOver() {
+		return nil, i18n.Message{}, ErrGameOver
+	}
+	if g.Rules.Mode == ModeSalvo {
+		return nil, i18n.Message{}, fmt.Errorf("%w: в режиме залпа стреляют через HandleSalvo", ErrWrongMode)
+	}
+
+	attack, err := g.attack(g.CurrentPlayer, Point{X: x, Y: y})
 	if err != nil {
 		fmt.Println("Ошибка:", err)
-		return ResultMiss, nil, "", err
+		return nil, i18n.Message{}, err
 	}
 
-	var msg string
-	switch result {
+	var msg i18n.Message
+	switch attack.Result {
 	case ResultHit:
-		msg = "Попадание! Вы ходите еще раз"
+		msg = i18n.M("attack.hit")
 	case ResultSunk:
-		msg = "Корабль потоплен! Вы ходите еще раз и вам добавлена способность!"
-		g.CurrentPlayer.AddRandomAbility()
+		msg = i18n.M("attack.sunk")
+		g.GrantRandomAbility(g.CurrentPlayer)
 	case ResultMiss:
-		msg = "Промах! Ход переходит"
+		msg = i18n.M("attack.miss")
+	case ResultBlocked:
+		msg = i18n.M("attack.blocked")
 	}
 
-	return result, markedPoints, msg, nil
+	if attack.BonusHit != nil && attack.Result == ResultHit {
+		msg = i18n.M("attack.double_damage")
+	}
+
+	return attack, msg, nil
 }
 
 func contains(points []Point, p Point) bool {
@@ -36,109 +48,15 @@ func contains(points []Point, p Point) bool {
 	return false
 }
 
-func (g *Game) findNextTarget() (Point, bool) {
-	computer := g.CurrentPlayer
-
-	availableTargets := g.findAvailableTargets(computer)
-	if len(availableTargets) > 0 {
-		randInd := rand.Intn(len(availableTargets))
-		return availableTargets[randInd], true
-	}
-
-	targetPoint := g.searchingNewTarget()
-	return targetPoint, false
-}
-
-func (g *Game) findAvailableTargets(computer *Player) []Point {
-	var availableTargets []Point
-
-	attackedSet := make(map[Point]bool)
-	for _, p := range computer.AllHits {
-		attackedSet[p] = true
-	}
-	for _, p := range computer.VerifiedPoints {
-		attackedSet[p] = true
-	}
-
-	for _, hitPoint := range computer.TargetHits {
-		directions := []Point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
-		for _, dir := range directions {
-			candidate := Point{X: hitPoint.X + dir.X, Y: hitPoint.Y + dir.Y}
-			if candidate.IsValidPoint() && !attackedSet[candidate] {
-				availableTargets = append(availableTargets, candidate)
-			}
-		}
-	}
-
-	return availableTargets
-}
-
-func (g *Game) searchingNewTarget() Point {
-	var targetPoint Point
-	computer := g.CurrentPlayer
-	for {
-		x, y := rand.Intn(10), rand.Intn(10)
-		targetPoint = Point{X: x, Y: y}
-
-		if !contains(computer.AllHits, targetPoint) && !contains(computer.VerifiedPoints, targetPoint) {
-			fmt.Printf("Режим поиска. Бот атакует клетку (%d, %d)\n", targetPoint.X, targetPoint.Y)
-			break
-		}
-	}
-	return targetPoint
-}
-
-func (g *Game) HandleComputerTurn() (Point, AttackResult, []Point, error) {
+func (g *Game) HandleComputerTurn() (*AttackResultData, error) {
 	computer := g.CurrentPlayer
-
-	var targetPoint Point
-	if computer.State == FinishingOff && len(computer.TargetHits) > 0 {
-		targetPoint, _ = g.findNextTarget()
-	} else {
-		targetPoint = g.searchingNewTarget()
-		computer.State = Searching
+	if computer.Strategy == nil {
+		return nil, ErrNotBotTurn
 	}
 
-	result, newlyMarkedPoints, err := computer.EnemyBoard.Attack(&targetPoint, computer)
+	targetPoint, err := computer.Strategy.NextTarget(g, computer)
 	if err != nil {
-		return targetPoint, ResultMiss, nil, err
-	}
-
-	switch result {
-	case ResultHit:
-		computer.AllHits = append(computer.AllHits, targetPoint)
-		computer.TargetHits = append(computer.TargetHits, targetPoint)
-		computer.State = FinishingOff
-
-	case ResultSunk:
-		computer.AllHits = append(computer.AllHits, targetPoint)
-		g.CurrentPlayer.shipSunkBot(newlyMarkedPoints)
-
-		computer.TargetHits = []Point{}
-		computer.State = Searching
-
-	case ResultMiss:
-		computer.VerifiedPoints = append(computer.VerifiedPoints, targetPoint)
-
-		if computer.State == FinishingOff && len(g.findAvailableTargets(computer)) == 0 {
-			computer.TargetHits = []Point{}
-			computer.State = Searching
-		}
+		return nil, err
 	}
-
-	return targetPoint, result, newlyMarkedPoints, nil
-}
-
-func (p *Player) shipSunkBot(makedPoints []Point) {
-	if p.Name == "Computer" {
-		for _, mp := range makedPoints {
-			if !contains(p.VerifiedPoints, mp) {
-				p.VerifiedPoints = append(p.VerifiedPoints, mp)
-			}
-		}
-	}
-}
-
-func (p Point) IsValidPoint() bool {
-	return p.X >= 0 && p.X < 10 && p.Y >= 0 && p.Y < 10
+	return g.attack(computer, targetPoint)
 }
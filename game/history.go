@@ -0,0 +1,295 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+type EventType string
+
+const (
+	EventPlacement    EventType = "placement"
+	EventAttack       EventType = "attack"
+	EventAbilityGrant EventType = "ability_granted"
+	EventAbilityUse   EventType = "ability_used"
+	EventTurnSwitch   EventType = "turn_switch"
+	EventGameOver     EventType = "game_over"
+)
+
+// Event - одна запись журнала партии. По журналу можно восстановить партию на любом ходу
+type Event struct {
+	Index   int               `json:"index"`
+	Type    EventType         `json:"type"`
+	Player  string            `json:"player"`
+	Ships   []Ship            `json:"ships,omitempty"`   // расстановка флота
+	Attack  *AttackResultData `json:"attack,omitempty"`  // выстрел и его результат
+	Ability string            `json:"ability,omitempty"` // ID полученной или примененной способности
+	Target  *AbilityTarget    `json:"target,omitempty"`  // цель способности
+	Winner  string            `json:"winner,omitempty"`
+}
+
+// replayableAbility - способность, действие которой не сводится к выстрелам из журнала
+// и должно быть повторено при восстановлении партии
+type replayableAbility interface {
+	replay(g *Game, p *Player, target *AbilityTarget)
+}
+
+func (g *Game) record(e Event) {
+	e.Index = len(g.History)
+	g.History = append(g.History, e)
+	if g.listener != nil {
+		g.listener(e)
+	}
+}
+
+// SetEventListener задает функцию, которая получает каждое событие журнала сразу после записи.
+// Вызывается под той же блокировкой, под которой меняется партия, поэтому не должна ждать
+func (g *Game) SetEventListener(fn func(Event)) {
+	g.listener = fn
+}
+
+func (g *Game) recordPlacement(p *Player) {
+	ships := make([]Ship, len(p.MyBoard.Ships))
+	for i, ship := range p.MyBoard.Ships {
+		ships[i] = Ship{
+			Size:       ship.Size,
+			IsVertical: ship.IsVertical,
+			Position:   append([]Point(nil), ship.Position...),
+		}
+	}
+	g.record(Event{Type: EventPlacement, Player: p.Name, Ships: ships})
+}
+
+// attack - выстрел игрока по полю противника с записью в журнал и уведомлением стратегии бота
+func (g *Game) attack(attacker *Player, target Point) (*AttackResultData, error) {
+	attack, err := attacker.EnemyBoard.Attack(&target, attacker)
+	if err != nil {
+		return nil, err
+	}
+
+	g.record(Event{Type: EventAttack, Player: attacker.Name, Attack: attack})
+	if attacker.Strategy != nil {
+		attacker.Strategy.Observe(attacker, attack)
+	}
+	return attack, nil
+}
+
+func (g *Game) GrantRandomAbility(p *Player) Ability {
+	p.AddRandomAbility(g.Rand())
+	ability := p.Abilities[len(p.Abilities)-1]
+	g.record(Event{Type: EventAbilityGrant, Player: p.Name, Ability: ability.ID()})
+	return ability
+}
+
+// UseAbility применяет способность игрока с номером index и убирает ее из списка
+func (g *Game) UseAbility(p *Player, index int, target *AbilityTarget) (*AbilityResult, error) {
+	if index < 0 || index >= len(p.Abilities) {
+		return nil, ErrNoSuchAbility
+	}
+
+	// события способности копятся без уведомлений и уходят слушателю, только если она сработала,
+	// иначе клиенты увидели бы ход, которого нет в журнале
+	ability := p.Abilities[index]
+	mark := len(g.History)
+	listener := g.listener
+	g.listener = nil
+	g.record(Event{Type: EventAbilityUse, Player: p.Name, Ability: ability.ID(), Target: target})
+
+	result, err := ability.Apply(g, target)
+	g.listener = listener
+	if err != nil {
+		g.History = g.History[:mark]
+		return nil, err
+	}
+
+	p.Abilities = append(p.Abilities[:index], p.Abilities[index+1:]...)
+	if listener != nil {
+		for _, e := range g.History[mark:] {
+			listener(e)
+		}
+	}
+	return result, nil
+}
+
+// CheckGameOver определяет победителя, когда у одного из игроков потоплен весь флот
+func (g *Game) CheckGameOver() (*Player, bool) {
+	if g.Winner != "" {
+		return g.playerByName(g.Winner), true
+	}
+
+	for _, p := range []*Player{g.Player1, g.Player2} {
+		if g.Opponent(p).MyBoard.AllShipSunk() {
+			g.Winner = p.Name
+			g.record(Event{Type: EventGameOver, Player: p.Name, Winner: p.Name})
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+func (g *Game) IsOver() bool {
+	return g.Winner != ""
+}
+
+// CheckTurn возвращает ErrGameOver или ErrNotYourTurn, если игрок p сейчас не может ходить
+func (g *Game) CheckTurn(p *Player) error {
+	if g.IsOver() {
+		return ErrGameOver
+	}
+	if g.CurrentPlayer != p {
+		return ErrNotYourTurn
+	}
+	return nil
+}
+
+// TurnCount возвращает число завершенных ходов - сколько раз ход переходил к другому игроку
+func (g *Game) TurnCount() int {
+	turns := 0
+	for _, e := range g.History {
+		if e.Type == EventTurnSwitch {
+			turns++
+		}
+	}
+	return turns
+}
+
+func (g *Game) playerByName(name string) *Player {
+	switch name {
+	case g.Player1.Name:
+		return g.Player1
+	case g.Player2.Name:
+		return g.Player2
+	}
+	return nil
+}
+
+// PublicHistory возвращает журнал без расстановки флота противника, пока партия не окончена
+func (g *Game) PublicHistory(viewer *Player) []Event {
+	events := make([]Event, len(g.History))
+	copy(events, g.History)
+
+	if g.IsOver() {
+		return events
+	}
+	for i := range events {
+		if events[i].Player == viewer.Name {
+			continue
+		}
+		switch events[i].Type {
+		case EventPlacement:
+			events[i].Ships = nil
+		case EventAbilityUse:
+			// защитные способности применяются к своему полю, их цели раскрыли бы флот
+			if info, _ := LookupAbility(events[i].Ability); info.OwnBoard {
+				events[i].Target = nil
+			}
+		}
+	}
+	return events
+}
+
+// ReplayTo восстанавливает партию после первых n событий журнала
+func (g *Game) ReplayTo(n int) (*Game, error) {
+	if n < 0 || n > len(g.History) {
+		return nil, fmt.Errorf("%w: номер хода должен быть от 0 до %d", ErrInvalidStep, len(g.History))
+	}
+	if len(g.History) == 0 || g.History[0].Type != EventPlacement {
+		return nil, ErrNoHistory
+	}
+
+	replay := &Game{
+		Rules:   g.Rules,
+		Player1: replayPlayer(g.Player1, g.Rules),
+		Player2: replayPlayer(g.Player2, g.Rules),
+	}
+	replay.seedRandom(g.Seed)
+	replay.Player1.EnemyBoard = replay.Player2.MyBoard
+	replay.Player2.EnemyBoard = replay.Player1.MyBoard
+	replay.CurrentPlayer = replay.Player1
+
+	for _, e := range g.History[:n] {
+		if err := replay.apply(e); err != nil {
+			return nil, fmt.Errorf("не удалось воспроизвести событие %d: %w", e.Index, err)
+		}
+	}
+	return replay, nil
+}
+
+func replayPlayer(p *Player, rules Rules) *Player {
+	replayed := &Player{
+		Name:      p.Name,
+		MyBoard:   NewBoard(rules),
+		Abilities: []Ability{},
+	}
+	if p.Strategy != nil {
+		replayed.Strategy = NewStrategy(p.Strategy.Difficulty())
+	}
+	return replayed
+}
+
+func (g *Game) apply(e Event) error {
+	p := g.playerByName(e.Player)
+	if p == nil {
+		return fmt.Errorf("неизвестный игрок %q", e.Player)
+	}
+
+	switch e.Type {
+	case EventPlacement:
+		for _, ship := range e.Ships {
+			s := Ship{Size: ship.Size, IsVertical: ship.IsVertical}
+			if len(ship.Position) == 0 {
+				return errors.New("у корабля нет позиции")
+			}
+			if err := p.MyBoard.placeShip(&s, ship.Position[0]); err != nil {
+				return err
+			}
+		}
+		g.recordPlacement(p)
+
+	case EventAttack:
+		if e.Attack == nil {
+			return errors.New("нет данных о выстреле")
+		}
+		if _, err := g.attack(p, e.Attack.Target); err != nil {
+			return err
+		}
+
+	case EventAbilityGrant:
+		ability, err := NewAbility(e.Ability)
+		if err != nil {
+			return err
+		}
+		p.Abilities = append(p.Abilities, ability)
+		g.record(e)
+
+	case EventAbilityUse:
+		index := -1
+		for i, ab := range p.Abilities {
+			if ab.ID() == e.Ability {
+				index = i
+				break
+			}
+		}
+		if index == -1 {
+			return fmt.Errorf("у игрока нет способности %q", e.Ability)
+		}
+
+		ability := p.Abilities[index]
+		p.Abilities = append(p.Abilities[:index], p.Abilities[index+1:]...)
+		if r, ok := ability.(replayableAbility); ok {
+			r.replay(g, p, e.Target)
+		}
+		g.record(e)
+
+	case EventTurnSwitch:
+		g.SwitchPlayer()
+
+	case EventGameOver:
+		g.Winner = e.Winner
+		g.record(e)
+
+	default:
+		return fmt.Errorf("неизвестный тип события %q", e.Type)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+)
+
+// второе слово состояния PCG выводится из зерна, чтобы партию можно было задать одним числом
+const seedStream = 0x9e3779b97f4a7c15
+
+func newSeed() uint64 {
+	for {
+		if seed := rand.Uint64(); seed != 0 {
+			return seed
+		}
+	}
+}
+
+// seedRandom задает партии зерно и заново создает ее генератор случайных чисел
+func (g *Game) seedRandom(seed uint64) {
+	if seed == 0 {
+		seed = newSeed()
+	}
+	g.Seed = seed
+	g.src = rand.NewPCG(seed, seed^seedStream)
+	g.rng = rand.New(g.src)
+}
+
+// Rand - генератор случайных чисел партии: расстановка, способности и ходы бота берутся только из него
+func (g *Game) Rand() *rand.Rand {
+	if g.rng == nil {
+		g.seedRandom(g.Seed)
+	}
+	return g.rng
+}
+
+type gameJSON Game
+
+// MarshalJSON сохраняет вместе с партией текущее состояние генератора,
+// чтобы после загрузки игра продолжилась так же, как продолжилась бы без сохранения
+func (g *Game) MarshalJSON() ([]byte, error) {
+	g.Rand()
+	state, err := g.src.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(struct {
+		*gameJSON
+		RandomState []byte
+	}{(*gameJSON)(g), state})
+}
+
+func (g *Game) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		*gameJSON
+		RandomState []byte
+	}{gameJSON: (*gameJSON)(g)}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	g.seedRandom(g.Seed)
+	if len(raw.RandomState) > 0 {
+		if err := g.src.UnmarshalBinary(raw.RandomState); err != nil {
+			return fmt.Errorf("неверное состояние генератора случайных чисел: %w", err)
+		}
+	}
+	return nil
+}
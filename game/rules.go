@@ -0,0 +1,81 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	minBoardSize = 5
+	maxBoardSize = 26
+)
+
+// RulePresets - готовые варианты правил, которые можно выбрать по имени
+var RulePresets = map[string]Rules{
+	"classic": {Width: 10, Height: 10, Fleet: []int{4, 3, 3, 2, 2, 2, 1, 1, 1, 1}},
+	"quick":   {Width: 8, Height: 8, Fleet: []int{3, 2, 2, 1, 1, 1}},
+	"big":     {Width: 15, Height: 15, Fleet: []int{5, 4, 4, 3, 3, 3, 2, 2, 2, 2, 1, 1, 1, 1, 1}},
+}
+
+func DefaultRules() Rules {
+	return RulePresets["classic"].clone()
+}
+
+func PresetRules(name string) (Rules, error) {
+	if name == "" {
+		return DefaultRules(), nil
+	}
+	rules, ok := RulePresets[name]
+	if !ok {
+		return Rules{}, fmt.Errorf("%w: неизвестный набор правил %s", ErrInvalidRules, name)
+	}
+	return rules.clone(), nil
+}
+
+func (r Rules) clone() Rules {
+	r.Fleet = append([]int(nil), r.Fleet...)
+	return r
+}
+
+func (r Rules) Validate() error {
+	switch r.Mode {
+	case "", ModeClassic, ModeSalvo:
+	default:
+		return fmt.Errorf("%w: неизвестный режим %q", ErrInvalidRules, r.Mode)
+	}
+	if r.Width < minBoardSize || r.Width > maxBoardSize || r.Height < minBoardSize || r.Height > maxBoardSize {
+		return fmt.Errorf("%w: размер поля должен быть от %d до %d клеток", ErrInvalidRules, minBoardSize, maxBoardSize)
+	}
+	if len(r.Fleet) == 0 {
+		return fmt.Errorf("%w: флот не может быть пустым", ErrInvalidRules)
+	}
+
+	longest := max(r.Width, r.Height)
+	area := 0
+	for _, size := range r.Fleet {
+		if size < 1 || size > longest {
+			return fmt.Errorf("%w: недопустимый размер корабля %d", ErrInvalidRules, size)
+		}
+		if r.AllowTouching {
+			area += size
+		} else {
+			area += (size + 1) * 2 // корабль вместе с обязательным зазором
+		}
+	}
+
+	capacity := r.Width * r.Height
+	if !r.AllowTouching {
+		capacity = (r.Width + 1) * (r.Height + 1)
+	}
+	if area > capacity {
+		return fmt.Errorf("%w: флот не помещается на поле", ErrInvalidRules)
+	}
+	return nil
+}
+
+// FleetSizes возвращает размеры кораблей флота по убыванию
+func (r Rules) FleetSizes() []int {
+	sizes := append([]int(nil), r.Fleet...)
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+	return sizes
+}
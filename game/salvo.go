@@ -0,0 +1,93 @@
+package game
+
+import "fmt"
+
+// ShotsPerTurn - сколько выстрелов игрок p делает за ход: в режиме залпа по одному на каждый его
+// непотопленный корабль, но не больше, чем осталось необстрелянных клеток
+func (g *Game) ShotsPerTurn(p *Player) int {
+	if g.Rules.Mode != ModeSalvo {
+		return 1
+	}
+	return min(p.MyBoard.ShipsLeft(), len(p.EnemyBoard.untouchedCells()))
+}
+
+// HandleSalvo - залп текущего игрока. Цели проверяются заранее, поэтому неверный залп не меняет партию.
+// После залпа ход переходит, переключить игрока должен вызывающий
+func (g *Game) HandleSalvo(targets []Point) ([]*AttackResultData, error) {
+	if g.IsOver() {
+		return nil, ErrGameOver
+	}
+	if g.Rules.Mode != ModeSalvo {
+		return nil, fmt.Errorf("%w: залпы доступны только в режиме %s", ErrWrongMode, ModeSalvo)
+	}
+
+	shooter := g.CurrentPlayer
+	board := shooter.EnemyBoard
+	if want := g.ShotsPerTurn(shooter); len(targets) != want {
+		return nil, fmt.Errorf("%w: нужно %d, получено %d", ErrShotCount, want, len(targets))
+	}
+	for i, p := range targets {
+		if !board.IsValidPoint(p) {
+			return nil, fmt.Errorf("%w: (%d, %d)", ErrOutOfBounds, p.X, p.Y)
+		}
+		if cell := board.Grid[p.X][p.Y]; cell == MissCell || cell == HitCell || contains(targets[:i], p) {
+			return nil, fmt.Errorf("%w: (%d, %d)", ErrAlreadyShot, p.X, p.Y)
+		}
+	}
+	return g.fireSalvo(shooter, targets)
+}
+
+// HandleComputerSalvo - залп бота, цели выбирает его стратегия
+func (g *Game) HandleComputerSalvo() ([]*AttackResultData, error) {
+	computer := g.CurrentPlayer
+	if computer.Strategy == nil {
+		return nil, ErrNotBotTurn
+	}
+	if g.Rules.Mode != ModeSalvo {
+		return nil, fmt.Errorf("%w: залпы доступны только в режиме %s", ErrWrongMode, ModeSalvo)
+	}
+
+	targets, err := computer.Strategy.NextSalvo(g, computer, g.ShotsPerTurn(computer))
+	if err != nil {
+		return nil, err
+	}
+	return g.fireSalvo(computer, targets)
+}
+
+// fireSalvo стреляет по целям по порядку. Клетки, открытые уже во время залпа
+// (вокруг потопленного корабля или вторым ударом двойного урона), пропускаются
+func (g *Game) fireSalvo(shooter *Player, targets []Point) ([]*AttackResultData, error) {
+	results := make([]*AttackResultData, 0, len(targets))
+	for _, p := range targets {
+		if cell := shooter.EnemyBoard.Grid[p.X][p.Y]; cell == MissCell || cell == HitCell {
+			continue
+		}
+
+		attack, err := g.attack(shooter, p)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, attack)
+
+		if attack.Result == ResultSunk && shooter.Strategy == nil {
+			g.GrantRandomAbility(shooter)
+		}
+		if shooter.EnemyBoard.AllShipSunk() {
+			break
+		}
+	}
+	return results, nil
+}
+
+// untouchedCells - клетки, по которым еще не стреляли
+func (b *Board) untouchedCells() []Point {
+	var cells []Point
+	for i := range b.Grid {
+		for j, cell := range b.Grid[i] {
+			if cell == EmptyCell || cell == ShipCell {
+				cells = append(cells, Point{X: i, Y: j})
+			}
+		}
+	}
+	return cells
+}
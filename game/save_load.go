@@ -3,34 +3,34 @@ package game
 import (
 	"encoding/json"
 	"fmt"
-	"os"
 )
 
 type AbilityDTO struct {
-	Name           string `json:"Name"`
-	RequiresTarget bool   `json:"RequiresTarget"`
+	ID                string `json:"id"`
+	Name              string `json:"Name"`
+	RequiresTarget    bool   `json:"RequiresTarget"`
+	RequiresDirection bool   `json:"RequiresDirection,omitempty"`
+	OwnBoard          bool   `json:"OwnBoard,omitempty"`
+}
+
+type StrategyDTO struct {
+	Difficulty Difficulty      `json:"difficulty"`
+	State      json.RawMessage `json:"state,omitempty"`
 }
 
 type RawPlayer struct {
 	Name            string
 	MyBoard         *Board
 	EnemyBoard      *Board
-	Abilities       []AbilityDTO `json:"Abilities"`
+	Abilities       []string `json:"abilities"` // ID способностей
 	HasDoubleDamage bool
-
-	State          AIState `json:"state"`           // поведение ИИ
-	AllHits        []Point `json:"all_hits"`        // все попадания
-	TargetHits     []Point `json:"target_hits"`     // добиваемый корабль
-	VerifiedPoints []Point `json:"verified_points"` // промахи
+	Strategy        *StrategyDTO `json:"strategy,omitempty"`
 }
 
 func (p *Player) MarshalJSON() ([]byte, error) {
-	abilities := make([]AbilityDTO, len(p.Abilities))
+	abilities := make([]string, len(p.Abilities))
 	for i, ability := range p.Abilities {
-		abilities[i] = AbilityDTO{
-			Name:           ability.Name(),
-			RequiresTarget: ability.RequiresTarget(),
-		}
+		abilities[i] = ability.ID()
 	}
 
 	raw := RawPlayer{
@@ -39,11 +39,14 @@ func (p *Player) MarshalJSON() ([]byte, error) {
 		EnemyBoard:      p.EnemyBoard,
 		Abilities:       abilities,
 		HasDoubleDamage: p.HasDoubleDamage,
+	}
 
-		State:          p.State,
-		AllHits:        p.AllHits,
-		TargetHits:     p.TargetHits,
-		VerifiedPoints: p.VerifiedPoints,
+	if p.Strategy != nil {
+		state, err := json.Marshal(p.Strategy)
+		if err != nil {
+			return nil, err
+		}
+		raw.Strategy = &StrategyDTO{Difficulty: p.Strategy.Difficulty(), State: state}
 	}
 
 	return json.Marshal(raw)
@@ -60,66 +63,93 @@ func (p *Player) UnmarshalJSON(data []byte) error {
 	p.EnemyBoard = raw.EnemyBoard
 	p.HasDoubleDamage = raw.HasDoubleDamage
 
-	p.State = raw.State
-	p.AllHits = raw.AllHits
-	p.TargetHits = raw.TargetHits
-	p.VerifiedPoints = raw.VerifiedPoints
+	p.Strategy = nil
+	if raw.Strategy != nil {
+		if _, err := ParseDifficulty(string(raw.Strategy.Difficulty)); err != nil {
+			return err
+		}
+		p.Strategy = NewStrategy(raw.Strategy.Difficulty)
+		if len(raw.Strategy.State) > 0 {
+			if err := json.Unmarshal(raw.Strategy.State, p.Strategy); err != nil {
+				return fmt.Errorf("не удалось восстановить состояние бота: %w", err)
+			}
+		}
+	}
 
 	p.Abilities = []Ability{}
-	for _, ab := range raw.Abilities {
-		switch ab.Name {
-		case "Артиллерийский удар":
-			p.Abilities = append(p.Abilities, &ArtilleryStrike{})
-		case "Сканнер":
-			p.Abilities = append(p.Abilities, &Scanner{})
-		case "Двойной урон":
-			p.Abilities = append(p.Abilities, &DoubleDamage{})
+	for _, id := range raw.Abilities {
+		ability, err := NewAbility(id)
+		if err != nil {
+			return fmt.Errorf("игрок %s: %w", p.Name, err)
 		}
+		p.Abilities = append(p.Abilities, ability)
 	}
 
 	return nil
 }
 
-func (g *Game) SaveGame(filename string) error {
-	data, err := json.MarshalIndent(g, "", " ")
+// SaveGame записывает партию в слот владельца owner; с непустым ключом сохранение подписывается HMAC
+func (g *Game) SaveGame(store Store, owner, slot string, key []byte) error {
+	data, err := EncodeGame(g, key)
 	if err != nil {
 		fmt.Println("Ошибка при попытке сохранить игру:", err)
 		return err
 	}
 
-	err = os.WriteFile(filename, data, 0644)
+	err = store.Save(owner, slot, data)
 	if err != nil {
-		fmt.Println("Ошибка при записи файла:", err)
+		fmt.Println("Ошибка при записи сохранения:", err)
 		return err
 	}
 
-	fmt.Println("Игра успешно сохранена в", filename)
+	fmt.Println("Игра успешно сохранена в слот", slot)
 	return nil
 }
 
-func LoadGame(filename string) (*Game, error) {
-	data, err := os.ReadFile(filename)
+// LoadGame читает партию из слота владельца owner. С непустым ключом принимаются только сохранения с верной подписью
+func LoadGame(store Store, owner, slot string, key []byte) (*Game, error) {
+	data, err := store.Load(owner, slot)
 	if err != nil {
 		fmt.Println("Ошибка при попытке загрузить игру:", err)
 		return nil, err
 	}
 
-	var game Game
-	err = json.Unmarshal(data, &game)
+	game, err := DecodeGame(data, key)
 	if err != nil {
-		fmt.Println("Ошибка при попытке чтения файла:", err)
+		fmt.Println("Ошибка при попытке чтения сохранения:", err)
 		return nil, err
 	}
 
-	game.Player1.EnemyBoard = game.Player2.MyBoard
-	game.Player2.EnemyBoard = game.Player1.MyBoard
+	fmt.Println("Игра успешно загружена")
+	return game, nil
+}
 
-	if game.CurrentPlayer.Name == game.Player1.Name {
-		game.CurrentPlayer = game.Player1
-	} else {
-		game.CurrentPlayer = game.Player2
+// DecodeGame восстанавливает партию из сохранения любой известной версии и заново связывает доски игроков
+func DecodeGame(data []byte, key []byte) (*Game, error) {
+	gameData, err := migrateSave(data, key)
+	if err != nil {
+		return nil, err
 	}
 
-	fmt.Println("Игра успешно загружена")
+	var game Game
+	if err := json.Unmarshal(gameData, &game); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptSave, err)
+	}
+
+	if game.Rules.Width == 0 {
+		// сохранения до появления правил всегда играли по классическим
+		game.Rules = DefaultRules()
+	}
+	if err := game.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptSave, err)
+	}
+
+	game.Player1.MyBoard.Rules = game.Rules
+	game.Player2.MyBoard.Rules = game.Rules
+
+	game.Player1.EnemyBoard = game.Player2.MyBoard
+	game.Player2.EnemyBoard = game.Player1.MyBoard
+	game.CurrentPlayer = game.playerByName(game.CurrentPlayer.Name)
+
 	return &game, nil
 }
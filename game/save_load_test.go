@@ -0,0 +1,27 @@
+package game
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSignedSaveRoundTrip(t *testing.T) {
+	key := []byte("secret")
+	store := NewMemoryStore()
+	g := playedGame(t)
+	if err := g.SaveGame(store, "owner", "slot", key); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadGame(store, "owner", "slot", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.Player1.MyBoard.Grid, g.Player1.MyBoard.Grid) {
+		t.Error("после загрузки доска игрока 1 не совпадает")
+	}
+	if _, err := LoadGame(store, "owner", "slot", []byte("other")); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("с чужим ключом LoadGame вернул %v, ожидалась ErrBadSignature", err)
+	}
+}
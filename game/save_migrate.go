@@ -0,0 +1,199 @@
+package game
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// SaveVersion - версия формата сохранения, которую пишет SaveGame.
+//
+//	1 - партия без обертки, способности и журнал ссылаются на русские названия способностей,
+//	    состояние бота в старых файлах лежит прямо в полях игрока
+//	2 - обертка {"version", "game"}, способности хранятся по постоянным ID
+const SaveVersion = 2
+
+type saveEnvelope struct {
+	Version   int             `json:"version"`
+	Game      json.RawMessage `json:"game"`
+	Signature string          `json:"signature,omitempty"` // HMAC-SHA256 версии и партии в hex
+}
+
+type saveFields map[string]json.RawMessage
+
+// saveMigrations[v] переводит партию из версии v в версию v+1
+var saveMigrations = map[int]func(game saveFields) error{
+	1: migrateV1ToV2,
+}
+
+// EncodeGame упаковывает партию в обертку текущей версии. Если задан ключ, обертка подписывается
+func EncodeGame(g *Game, key []byte) ([]byte, error) {
+	data, err := json.Marshal(g)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := saveEnvelope{Version: SaveVersion, Game: data}
+	if len(key) > 0 {
+		envelope.Signature = hex.EncodeToString(signSave(key, envelope.Version, data))
+	}
+	return json.MarshalIndent(envelope, "", " ")
+}
+
+// signSave считает подпись по сжатому JSON партии, чтобы отступы в файле на нее не влияли
+func signSave(key []byte, version int, game []byte) []byte {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, game); err != nil {
+		compact.Reset()
+		compact.Write(game)
+	}
+
+	mac := hmac.New(sha256.New, key)
+	fmt.Fprintf(mac, "sea_battle/v%d\n", version)
+	mac.Write(compact.Bytes())
+	return mac.Sum(nil)
+}
+
+// migrateSave снимает обертку, проверяет подпись, если задан ключ, и по цепочке миграций
+// доводит партию до текущей версии. С ключом неподписанные сохранения не принимаются
+func migrateSave(data []byte, key []byte) ([]byte, error) {
+	var envelope saveEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptSave, err)
+	}
+	if envelope.Game == nil {
+		// сохранения первой версии - сама партия без обертки
+		envelope = saveEnvelope{Version: 1, Game: data}
+	}
+
+	if envelope.Version < 1 || envelope.Version > SaveVersion {
+		return nil, fmt.Errorf("%w %d: сервер поддерживает версии с 1 по %d", ErrUnknownSaveVersion, envelope.Version, SaveVersion)
+	}
+
+	if len(key) > 0 {
+		if envelope.Signature == "" {
+			return nil, fmt.Errorf("%w: сохранение не подписано", ErrBadSignature)
+		}
+		signature, err := hex.DecodeString(envelope.Signature)
+		if err != nil || !hmac.Equal(signature, signSave(key, envelope.Version, envelope.Game)) {
+			return nil, fmt.Errorf("%w: файл изменен или подписан другим ключом", ErrBadSignature)
+		}
+	}
+	if envelope.Version == SaveVersion {
+		return envelope.Game, nil
+	}
+
+	var game saveFields
+	if err := json.Unmarshal(envelope.Game, &game); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptSave, err)
+	}
+	for v := envelope.Version; v < SaveVersion; v++ {
+		if err := saveMigrations[v](game); err != nil {
+			return nil, fmt.Errorf("%w: перевод с версии %d на %d: %w", ErrCorruptSave, v, v+1, err)
+		}
+	}
+	return json.Marshal(game)
+}
+
+func migrateV1ToV2(game saveFields) error {
+	// в первой версии бот всегда сидел на месте Player2, а CurrentPlayer - копия одного из игроков
+	botName := legacyPlayerName(game["Player2"])
+	for _, key := range []string{"Player1", "Player2", "CurrentPlayer"} {
+		if raw, ok := game[key]; ok {
+			isBot := key == "Player2" || key == "CurrentPlayer" && legacyPlayerName(raw) == botName
+			migrated, err := migratePlayerV1(raw, isBot)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			game[key] = migrated
+		}
+	}
+
+	if raw, ok := game["History"]; ok {
+		var history []saveFields
+		if err := json.Unmarshal(raw, &history); err != nil {
+			return err
+		}
+		for i, e := range history {
+			rawName, ok := e["ability"]
+			if !ok {
+				continue
+			}
+			var name string
+			if err := json.Unmarshal(rawName, &name); err != nil {
+				return err
+			}
+			id, err := legacyAbilityID(name)
+			if err != nil {
+				return fmt.Errorf("событие %d: %w", i, err)
+			}
+			e["ability"], _ = json.Marshal(id)
+		}
+
+		migrated, err := json.Marshal(history)
+		if err != nil {
+			return err
+		}
+		game["History"] = migrated
+	}
+	return nil
+}
+
+// legacyPlayerName достает имя игрока из сохранения первой версии
+func legacyPlayerName(data json.RawMessage) string {
+	var player struct{ Name string }
+	if json.Unmarshal(data, &player) != nil {
+		return ""
+	}
+	return player.Name
+}
+
+func migratePlayerV1(data json.RawMessage, isBot bool) (json.RawMessage, error) {
+	var player saveFields
+	if err := json.Unmarshal(data, &player); err != nil {
+		return nil, err
+	}
+
+	var abilities []struct{ Name string }
+	if raw, ok := player["Abilities"]; ok {
+		if err := json.Unmarshal(raw, &abilities); err != nil {
+			return nil, err
+		}
+	}
+	ids := make([]string, len(abilities))
+	for i, ab := range abilities {
+		id, err := legacyAbilityID(ab.Name)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	delete(player, "Abilities")
+	player["abilities"], _ = json.Marshal(ids)
+
+	// до появления стратегий бот всегда играл в режиме поиска и добивания, а его память
+	// хранилась прямо в полях игрока
+	legacyBot := []string{"state", "all_hits", "target_hits", "verified_points"}
+	_, hasStrategy := player["strategy"]
+	if isBot && !hasStrategy {
+		state := saveFields{}
+		for _, key := range legacyBot {
+			if raw, ok := player[key]; ok {
+				state[key] = raw
+			}
+		}
+		stateData, err := json.Marshal(state)
+		if err != nil {
+			return nil, err
+		}
+		player["strategy"], _ = json.Marshal(StrategyDTO{Difficulty: Medium, State: stateData})
+	}
+	for _, key := range legacyBot {
+		delete(player, key)
+	}
+
+	return json.Marshal(player)
+}
@@ -0,0 +1,36 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMigrateV1DetectsBotBySeat(t *testing.T) {
+	// у человека в старом файле тоже могут лежать непустые all_hits
+	human := `{"Name":"Computer fan","Abilities":[],"all_hits":[{"X":1,"Y":2}],"verified_points":[]}`
+	bot := `{"Name":"Computer","Abilities":[],"all_hits":null,"state":0}`
+	legacy := `{"Player1":` + human + `,"Player2":` + bot + `,"CurrentPlayer":` + bot + `}`
+
+	data, err := migrateSave([]byte(legacy), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var game map[string]saveFields
+	if err := json.Unmarshal(data, &game); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := game["Player1"]["strategy"]; ok {
+		t.Error("человеку на месте Player1 досталась стратегия бота")
+	}
+	for _, key := range []string{"Player2", "CurrentPlayer"} {
+		if _, ok := game[key]["strategy"]; !ok {
+			t.Errorf("%s: бот остался без стратегии", key)
+		}
+	}
+	for key, player := range game {
+		if _, ok := player["all_hits"]; ok {
+			t.Errorf("%s: поле all_hits не удалено", key)
+		}
+	}
+}
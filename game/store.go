@@ -0,0 +1,53 @@
+package game
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// имя слота может стать именем файла, поэтому разрешены только буквы, цифры, '-' и '_'
+var slotNamePattern = regexp.MustCompile(`^[\p{L}\p{N}_-]{1,64}$`)
+
+// владелец слотов тоже может стать именем каталога; пустой владелец - общие слоты
+var ownerNamePattern = regexp.MustCompile(`^[\p{L}\p{N}_-]{0,64}$`)
+
+// StoredSave - слот хранилища и время последней записи в него
+type StoredSave struct {
+	Slot    string
+	SavedAt time.Time
+}
+
+// Store хранит закодированные сохранения партий по владельцу и имени слота: одноименные слоты
+// разных владельцев не пересекаются. Кодирование, подпись и миграции остаются в SaveGame и LoadGame,
+// хранилище работает только с байтами
+type Store interface {
+	Save(owner, slot string, data []byte) error
+	Load(owner, slot string) ([]byte, error) // ErrSaveNotFound, если слот пуст
+	Delete(owner, slot string) error         // ErrSaveNotFound, если слот пуст
+	List(owner string) ([]StoredSave, error) // только слоты владельца owner
+}
+
+// ValidateSlot проверяет имя слота. Хранилища вызывают ее сами, но серверу удобнее
+// отклонить плохое имя до обращения к хранилищу
+func ValidateSlot(slot string) error {
+	if !slotNamePattern.MatchString(slot) {
+		return fmt.Errorf("%w %q: разрешены буквы, цифры, '-' и '_' (до 64 символов)", ErrInvalidSlot, slot)
+	}
+	return nil
+}
+
+func validateOwner(owner string) error {
+	if !ownerNamePattern.MatchString(owner) {
+		return fmt.Errorf("%w: недопустимый владелец %q", ErrInvalidSlot, owner)
+	}
+	return nil
+}
+
+// validateKey проверяет владельца и слот перед обращением к хранилищу
+func validateKey(owner, slot string) error {
+	if err := validateOwner(owner); err != nil {
+		return err
+	}
+	return ValidateSlot(slot)
+}
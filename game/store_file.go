@@ -0,0 +1,116 @@
+package game
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const saveFileExt = ".json"
+
+// FileStore хранит каждый слот в отдельном файле <владелец>/<слот>.json внутри каталога Dir.
+// Общие слоты (пустой владелец) лежат прямо в Dir, как и сохранения до появления владельцев
+type FileStore struct {
+	Dir string
+	mu  sync.Mutex
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileStore{Dir: dir}, nil
+}
+
+func (s *FileStore) path(owner, slot string) (string, error) {
+	if err := validateKey(owner, slot); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.Dir, owner, slot+saveFileExt), nil
+}
+
+func (s *FileStore) Save(owner, slot string, data []byte) error {
+	path, err := s.path(owner, slot)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	// запись через временный файл, чтобы оборванная запись не испортила прежнее сохранение
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *FileStore) Load(owner, slot string) ([]byte, error) {
+	path, err := s.path(owner, slot)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrSaveNotFound
+	}
+	return data, err
+}
+
+func (s *FileStore) Delete(owner, slot string) error {
+	path, err := s.path(owner, slot)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrSaveNotFound
+	}
+	return err
+}
+
+// List возвращает слоты каталога владельца; файлы с неподходящими именами пропускаются
+func (s *FileStore) List(owner string) ([]StoredSave, error) {
+	if err := validateOwner(owner); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(filepath.Join(s.Dir, owner))
+	if errors.Is(err, os.ErrNotExist) {
+		return []StoredSave{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	saves := []StoredSave{}
+	for _, entry := range entries {
+		slot, ok := strings.CutSuffix(entry.Name(), saveFileExt)
+		if entry.IsDir() || !ok || ValidateSlot(slot) != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		saves = append(saves, StoredSave{Slot: slot, SavedAt: info.ModTime()})
+	}
+	return saves, nil
+}
@@ -0,0 +1,72 @@
+package game
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryStore держит сохранения в памяти процесса: для тестов и серверов без диска
+type MemoryStore struct {
+	mu    sync.Mutex
+	saves map[memoryKey]memorySave
+}
+
+type memoryKey struct {
+	owner, slot string
+}
+
+type memorySave struct {
+	data    []byte
+	savedAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{saves: make(map[memoryKey]memorySave)}
+}
+
+func (s *MemoryStore) Save(owner, slot string, data []byte) error {
+	if err := validateKey(owner, slot); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saves[memoryKey{owner, slot}] = memorySave{data: append([]byte(nil), data...), savedAt: time.Now()}
+	return nil
+}
+
+func (s *MemoryStore) Load(owner, slot string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	save, ok := s.saves[memoryKey{owner, slot}]
+	if !ok {
+		return nil, ErrSaveNotFound
+	}
+	return append([]byte(nil), save.data...), nil
+}
+
+func (s *MemoryStore) Delete(owner, slot string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := memoryKey{owner, slot}
+	if _, ok := s.saves[key]; !ok {
+		return ErrSaveNotFound
+	}
+	delete(s.saves, key)
+	return nil
+}
+
+func (s *MemoryStore) List(owner string) ([]StoredSave, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saves := []StoredSave{}
+	for key, save := range s.saves {
+		if key.owner == owner {
+			saves = append(saves, StoredSave{Slot: key.slot, SavedAt: save.savedAt})
+		}
+	}
+	return saves, nil
+}
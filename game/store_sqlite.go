@@ -0,0 +1,121 @@
+package game
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS saves (
+	owner    TEXT NOT NULL DEFAULT '',
+	slot     TEXT NOT NULL,
+	data     BLOB NOT NULL,
+	saved_at INTEGER NOT NULL,
+	PRIMARY KEY (owner, slot)
+)`
+
+// в базах до появления владельцев ключом был один slot; их слоты становятся общими
+const sqliteAddOwner = `ALTER TABLE saves RENAME TO saves_old;
+` + sqliteSchema + `;
+INSERT INTO saves (owner, slot, data, saved_at) SELECT '', slot, data, saved_at FROM saves_old;
+DROP TABLE saves_old`
+
+// SQLiteStore хранит все слоты в одной таблице встроенной базы SQLite
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite не любит параллельную запись из нескольких соединений
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := migrateSQLiteOwner(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// migrateSQLiteOwner переводит таблицу старого формата на ключ (owner, slot)
+func migrateSQLiteOwner(db *sql.DB) error {
+	var hasOwner bool
+	err := db.QueryRow(`SELECT COUNT(*) > 0 FROM pragma_table_info('saves') WHERE name = 'owner'`).Scan(&hasOwner)
+	if err != nil || hasOwner {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(sqliteAddOwner); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) Save(owner, slot string, data []byte) error {
+	if err := validateKey(owner, slot); err != nil {
+		return err
+	}
+
+	_, err := s.db.Exec(`INSERT INTO saves (owner, slot, data, saved_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (owner, slot) DO UPDATE SET data = excluded.data, saved_at = excluded.saved_at`,
+		owner, slot, data, time.Now().UnixNano())
+	return err
+}
+
+func (s *SQLiteStore) Load(owner, slot string) ([]byte, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT data FROM saves WHERE owner = ? AND slot = ?`, owner, slot).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSaveNotFound
+	}
+	return data, err
+}
+
+func (s *SQLiteStore) Delete(owner, slot string) error {
+	res, err := s.db.Exec(`DELETE FROM saves WHERE owner = ? AND slot = ?`, owner, slot)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrSaveNotFound
+	}
+	return err
+}
+
+func (s *SQLiteStore) List(owner string) ([]StoredSave, error) {
+	rows, err := s.db.Query(`SELECT slot, saved_at FROM saves WHERE owner = ?`, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	saves := []StoredSave{}
+	for rows.Next() {
+		var save StoredSave
+		var savedAt int64
+		if err := rows.Scan(&save.Slot, &savedAt); err != nil {
+			return nil, err
+		}
+		save.SavedAt = time.Unix(0, savedAt)
+		saves = append(saves, save)
+	}
+	return saves, rows.Err()
+}
@@ -0,0 +1,82 @@
+package game
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func testStores(t *testing.T) map[string]Store {
+	t.Helper()
+	fs, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := NewSQLiteStore(filepath.Join(t.TempDir(), "saves.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return map[string]Store{"memory": NewMemoryStore(), "fs": fs, "sqlite": db}
+}
+
+func TestStoreOwnersDoNotShareSlots(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := store.Save("alice", "default", []byte("a")); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Save("", "default", []byte("shared")); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := store.Load("bob", "default"); !errors.Is(err, ErrSaveNotFound) {
+				t.Errorf("чужой слот загрузился: %v", err)
+			}
+			if err := store.Delete("bob", "default"); !errors.Is(err, ErrSaveNotFound) {
+				t.Errorf("чужой слот удален: %v", err)
+			}
+			if data, err := store.Load("alice", "default"); err != nil || string(data) != "a" {
+				t.Errorf("Load(alice) = %q, %v", data, err)
+			}
+
+			for owner, want := range map[string]int{"alice": 1, "bob": 0, "": 1} {
+				saves, err := store.List(owner)
+				if err != nil || len(saves) != want {
+					t.Errorf("List(%q) = %v, %v; ожидалось слотов: %d", owner, saves, err, want)
+				}
+			}
+
+			if err := store.Save("../bob", "default", nil); !errors.Is(err, ErrInvalidSlot) {
+				t.Errorf("недопустимый владелец принят: %v", err)
+			}
+		})
+	}
+}
+
+func TestSQLiteStoreMigratesSharedSlots(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`CREATE TABLE saves (slot TEXT PRIMARY KEY, data BLOB NOT NULL, saved_at INTEGER NOT NULL);
+		INSERT INTO saves VALUES ('default', 'old', 1)`)
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if data, err := store.Load("", "default"); err != nil || string(data) != "old" {
+		t.Fatalf("старый слот после миграции: %q, %v", data, err)
+	}
+	if err := store.Save("alice", "default", []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,259 @@
+package game
+
+import (
+	"fmt"
+	"math/rand/v2"
+)
+
+func ParseDifficulty(s string) (Difficulty, error) {
+	switch Difficulty(s) {
+	case "":
+		return DefaultDifficulty, nil
+	case Easy, Medium, Hard:
+		return Difficulty(s), nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnknownDifficulty, s)
+}
+
+func NewStrategy(level Difficulty) Strategy {
+	switch level {
+	case Easy:
+		return &EasyStrategy{}
+	case Medium:
+		return &MediumStrategy{shotMemory: newShotMemory(), State: Searching}
+	default:
+		return &HardStrategy{shotMemory: newShotMemory()}
+	}
+}
+
+// Difficulty возвращает уровень бота, а для партии двух людей - уровень по умолчанию
+func (g *Game) Difficulty() Difficulty {
+	if g.Player2.Strategy != nil {
+		return g.Player2.Strategy.Difficulty()
+	}
+	return DefaultDifficulty
+}
+
+func newShotMemory() shotMemory {
+	return shotMemory{
+		AllHits:        []Point{},
+		TargetHits:     []Point{},
+		VerifiedPoints: []Point{},
+	}
+}
+
+// restoreStrategy создает бота, память которого восстановлена по полю противника:
+// подбитые клетки недобитых кораблей становятся целью добивания
+func restoreStrategy(level Difficulty, enemy *Board) Strategy {
+	memory := newShotMemory()
+	for x, row := range enemy.Grid {
+		for y, cell := range row {
+			switch cell {
+			case HitCell:
+				memory.AllHits = append(memory.AllHits, Point{X: x, Y: y})
+			case MissCell:
+				memory.VerifiedPoints = append(memory.VerifiedPoints, Point{X: x, Y: y})
+			}
+		}
+	}
+	for _, ship := range enemy.Ships {
+		if ship.IsSunk {
+			continue
+		}
+		for _, p := range ship.Position {
+			if enemy.Grid[p.X][p.Y] == HitCell {
+				memory.TargetHits = append(memory.TargetHits, p)
+			}
+		}
+	}
+
+	switch strategy := NewStrategy(level).(type) {
+	case *MediumStrategy:
+		strategy.shotMemory = memory
+		if len(memory.TargetHits) > 0 {
+			strategy.State = FinishingOff
+		}
+		return strategy
+	case *HardStrategy:
+		strategy.shotMemory = memory
+		return strategy
+	default:
+		return strategy
+	}
+}
+
+func (m *shotMemory) isAttacked(p Point) bool {
+	return contains(m.AllHits, p) || contains(m.VerifiedPoints, p) || contains(m.planned, p)
+}
+
+// planSalvo набирает n целей, по очереди спрашивая next. Уже выбранные цели считаются
+// обстрелянными, поэтому next не повторяет их, а ищет следующую клетку
+func (m *shotMemory) planSalvo(n int, next func() (Point, error)) ([]Point, error) {
+	defer func() { m.planned = nil }()
+	for len(m.planned) < n {
+		p, err := next()
+		if err != nil {
+			return nil, err
+		}
+		m.planned = append(m.planned, p)
+	}
+	return append([]Point(nil), m.planned...), nil
+}
+
+func (m *shotMemory) remember(attack *AttackResultData) {
+	switch attack.Result {
+	case ResultHit:
+		m.AllHits = append(m.AllHits, attack.Target)
+		m.TargetHits = append(m.TargetHits, attack.Target)
+		if attack.BonusHit != nil {
+			m.AllHits = append(m.AllHits, *attack.BonusHit)
+			m.TargetHits = append(m.TargetHits, *attack.BonusHit)
+		}
+
+	case ResultSunk:
+		m.AllHits = append(m.AllHits, attack.Target)
+		for _, mp := range attack.MarkedPoints {
+			if !contains(m.VerifiedPoints, mp) {
+				m.VerifiedPoints = append(m.VerifiedPoints, mp)
+			}
+		}
+		m.TargetHits = []Point{}
+
+	case ResultMiss:
+		m.VerifiedPoints = append(m.VerifiedPoints, attack.Target)
+	}
+}
+
+// randomUntouched выбирает случайную клетку, по которой еще не стреляли,
+// или возвращает ErrNoTargets, если таких клеток нет
+func (m *shotMemory) randomUntouched(rng *rand.Rand, enemy *Board) (Point, error) {
+	if !m.hasUntouched(enemy) {
+		return Point{}, ErrNoTargets
+	}
+
+	var targetPoint Point
+	for {
+		x, y := rng.IntN(enemy.Rules.Height), rng.IntN(enemy.Rules.Width)
+		targetPoint = Point{X: x, Y: y}
+
+		if !m.isAttacked(targetPoint) {
+			fmt.Printf("Режим поиска. Бот атакует клетку (%d, %d)\n", targetPoint.X, targetPoint.Y)
+			break
+		}
+	}
+	return targetPoint, nil
+}
+
+func (m *shotMemory) hasUntouched(enemy *Board) bool {
+	for x := range enemy.Grid {
+		for y := range enemy.Grid[x] {
+			if !m.isAttacked(Point{X: x, Y: y}) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// forget убирает отремонтированную противником клетку из попаданий, чтобы по ней снова можно было стрелять
+func (m *shotMemory) forget(p Point) {
+	m.AllHits, _ = removePoint(m.AllHits, p)
+	m.TargetHits, _ = removePoint(m.TargetHits, p)
+}
+
+func (e *EasyStrategy) Difficulty() Difficulty {
+	return Easy
+}
+
+func (e *EasyStrategy) NextTarget(g *Game, self *Player) (Point, error) {
+	targets := self.EnemyBoard.untouchedCells()
+	if len(targets) == 0 {
+		return Point{}, ErrNoTargets
+	}
+	return targets[g.Rand().IntN(len(targets))], nil
+}
+
+func (e *EasyStrategy) NextSalvo(g *Game, self *Player, n int) ([]Point, error) {
+	targets := self.EnemyBoard.untouchedCells()
+	if len(targets) == 0 {
+		return nil, ErrNoTargets
+	}
+	g.Rand().Shuffle(len(targets), func(i, j int) { targets[i], targets[j] = targets[j], targets[i] })
+	return targets[:min(n, len(targets))], nil
+}
+
+func (e *EasyStrategy) Observe(self *Player, attack *AttackResultData) {}
+
+func (m *MediumStrategy) Difficulty() Difficulty {
+	return Medium
+}
+
+func (m *MediumStrategy) NextTarget(g *Game, self *Player) (Point, error) {
+	if m.State == FinishingOff && len(m.TargetHits) > 0 {
+		availableTargets := m.findAvailableTargets(self.EnemyBoard)
+		if len(availableTargets) > 0 {
+			return availableTargets[g.Rand().IntN(len(availableTargets))], nil
+		}
+	}
+
+	m.State = Searching
+	return m.randomUntouched(g.Rand(), self.EnemyBoard)
+}
+
+func (m *MediumStrategy) NextSalvo(g *Game, self *Player, n int) ([]Point, error) {
+	// при планировании соседние клетки кончаются раньше, чем корабль потоплен, - состояние добивания
+	// не должно сбрасываться до выстрелов
+	defer func(state AIState) { m.State = state }(m.State)
+	return m.planSalvo(n, func() (Point, error) { return m.NextTarget(g, self) })
+}
+
+func (m *MediumStrategy) Observe(self *Player, attack *AttackResultData) {
+	m.remember(attack)
+
+	switch attack.Result {
+	case ResultHit:
+		m.State = FinishingOff
+	case ResultSunk:
+		m.State = Searching
+	case ResultMiss:
+		if m.State == FinishingOff && len(m.findAvailableTargets(self.EnemyBoard)) == 0 {
+			m.TargetHits = []Point{}
+			m.State = Searching
+		}
+	}
+}
+
+func (m *MediumStrategy) findAvailableTargets(enemy *Board) []Point {
+	var availableTargets []Point
+
+	for _, hitPoint := range m.TargetHits {
+		directions := []Point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+		for _, dir := range directions {
+			candidate := Point{X: hitPoint.X + dir.X, Y: hitPoint.Y + dir.Y}
+			if enemy.IsValidPoint(candidate) && !m.isAttacked(candidate) && !contains(availableTargets, candidate) {
+				availableTargets = append(availableTargets, candidate)
+			}
+		}
+	}
+
+	return availableTargets
+}
+
+func (h *HardStrategy) Difficulty() Difficulty {
+	return Hard
+}
+
+func (h *HardStrategy) NextTarget(g *Game, self *Player) (Point, error) {
+	if target, ok := h.densityTarget(g.Rand(), self.EnemyBoard); ok {
+		return target, nil
+	}
+	return h.randomUntouched(g.Rand(), self.EnemyBoard)
+}
+
+func (h *HardStrategy) NextSalvo(g *Game, self *Player, n int) ([]Point, error) {
+	return h.planSalvo(n, func() (Point, error) { return h.NextTarget(g, self) })
+}
+
+func (h *HardStrategy) Observe(self *Player, attack *AttackResultData) {
+	h.remember(attack)
+}
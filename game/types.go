@@ -1,5 +1,10 @@
 package game
 
+import (
+	"math/rand/v2"
+	"sea_battle/i18n"
+)
+
 type CellState int
 
 const (
@@ -15,6 +20,7 @@ const (
 	ResultMiss AttackResult = iota
 	ResultHit
 	ResultSunk
+	ResultBlocked // выстрел отражен щитом, сегмент цел
 )
 
 type Point struct {
@@ -30,9 +36,29 @@ type Ship struct {
 	Position   []Point
 }
 
+// Rules - размеры поля, состав флота и порядок стрельбы партии
+type Rules struct {
+	Width         int      `json:"width"`
+	Height        int      `json:"height"`
+	Fleet         []int    `json:"fleet"`
+	AllowTouching bool     `json:"allow_touching"` // разрешено ли кораблям касаться друг друга
+	Mode          GameMode `json:"mode,omitempty"` // пусто - ModeClassic
+}
+
+// GameMode - правило, по которому игроки делают выстрелы
+type GameMode string
+
+const (
+	ModeClassic GameMode = "classic" // один выстрел, после попадания игрок стреляет еще раз
+	ModeSalvo   GameMode = "salvo"   // залп из стольких выстрелов, сколько у игрока непотопленных кораблей, затем ход переходит
+)
+
 type Board struct {
-	Grid  [10][10]CellState
-	Ships []Ship
+	Grid    [][]CellState // Grid[X][Y], X - строка, Y - столбец
+	Ships   []Ship
+	Shields []Point `json:"shields,omitempty"` // целые сегменты, которые отразят следующее попадание
+	Decoys  []Point `json:"decoys,omitempty"`  // ложные цели: пустая клетка - еще ждет выстрела, подбитая - уже сработала
+	Rules   Rules   `json:"-"`                 // восстанавливается из правил партии при загрузке
 }
 
 type AttackObserver interface {
@@ -45,17 +71,46 @@ type Player struct {
 	EnemyBoard      *Board
 	Abilities       []Ability
 	HasDoubleDamage bool
-
-	State          AIState `json:"state"`           // поведение ИИ
-	AllHits        []Point `json:"all_hits"`        // все попадания
-	TargetHits     []Point `json:"target_hits"`     // добиваемый корабль
-	VerifiedPoints []Point `json:"verified_points"` // промахи
+	Strategy        Strategy // nil, если игроком управляет человек
 }
 
 type Game struct {
+	Rules         Rules
 	Player1       *Player
 	Player2       *Player
 	CurrentPlayer *Player
+	Winner        string  `json:",omitempty"`
+	History       []Event `json:",omitempty"`
+	Seed          uint64  // зерно генератора, по которому партию можно повторить
+
+	src      *rand.PCG
+	rng      *rand.Rand
+	listener func(Event)
+}
+
+// Options - параметры новой партии
+type Options struct {
+	Rules      Rules
+	Difficulty Difficulty
+	Seed       uint64 // 0 - выбрать зерно случайно
+}
+
+type Difficulty string
+
+const (
+	Easy   Difficulty = "easy"   // случайная стрельба
+	Medium Difficulty = "medium" // случайный поиск и добивание соседних клеток
+	Hard   Difficulty = "hard"   // стрельба по карте плотности возможных расстановок
+
+	DefaultDifficulty = Hard
+)
+
+// Strategy - поведение бота: выбор следующей цели и учет результатов своих выстрелов
+type Strategy interface {
+	Difficulty() Difficulty
+	NextTarget(g *Game, self *Player) (Point, error)         // ErrNoTargets, если стрелять некуда
+	NextSalvo(g *Game, self *Player, n int) ([]Point, error) // n разных целей, по которым еще не стреляли
+	Observe(self *Player, attack *AttackResultData)
 }
 
 type AIState int
@@ -65,24 +120,80 @@ const (
 	FinishingOff                // добивание подбитого корабля
 )
 
+// shotMemory - то, что бот знает о поле противника по своим выстрелам
+type shotMemory struct {
+	AllHits        []Point `json:"all_hits"`        // все попадания
+	TargetHits     []Point `json:"target_hits"`     // добиваемый корабль
+	VerifiedPoints []Point `json:"verified_points"` // промахи и клетки вокруг потопленных кораблей
+
+	planned []Point // цели планируемого залпа, до выстрела считаются обстрелянными
+}
+
+type EasyStrategy struct{}
+
+type MediumStrategy struct {
+	shotMemory
+	State AIState `json:"state"` // поведение ИИ
+}
+
+type HardStrategy struct {
+	shotMemory
+}
+
 type AbilityResult struct {
-	Message        string            `json:"message"`
-	AffectedPoints []Point           `json:"affected_points,omitempty"`
-	AttackResult   *AttackResultData `json:"attack_result,omitempty"`
+	Message        i18n.Message        `json:"message"`
+	AffectedPoints []Point             `json:"affected_points,omitempty"`
+	AttackResults  []*AttackResultData `json:"attack_results,omitempty"` // выстрелы способности в порядке их разрешения
+}
+
+// AbilityTarget - цель способности: клетка и, если способность движется по полю, направление
+type AbilityTarget struct {
+	Point
+	Direction Direction `json:"direction,omitempty"`
 }
 
+// Direction - направление по полю: up и down меняют строку X, left и right - столбец Y
+type Direction string
+
+const (
+	DirUp    Direction = "up"
+	DirDown  Direction = "down"
+	DirLeft  Direction = "left"
+	DirRight Direction = "right"
+)
+
 type AttackResultData struct {
 	Target       Point        `json:"target"`
 	Result       AttackResult `json:"result"`
 	MarkedPoints []Point      `json:"marked_points,omitempty"`
+	DoubleDamage bool         `json:"double_damage,omitempty"` // выстрел был усилен двойным уроном
+	BonusHit     *Point       `json:"bonus_hit,omitempty"`     // соседний сегмент, подбитый двойным уроном
 }
 
 type ArtilleryStrike struct{}
 type Scanner struct{}
 type DoubleDamage struct{}
+type Torpedo struct{}
+type AirStrike struct{}
+type CarpetBomb struct{}
+type DiagonalStrike struct{}
+type Shield struct{}
+type Decoy struct{}
+type Repair struct{}
 
 type Ability interface {
-	Apply(g *Game, target *Point) (*AbilityResult, error)
-	Name() string
-	RequiresTarget() bool
+	Apply(g *Game, target *AbilityTarget) (*AbilityResult, error)
+	ID() string // постоянный идентификатор для сохранений, журнала и API; остальное о способности - в реестре
+}
+
+// AbilityInfo - запись реестра способностей. Новая способность - это ее тип и одна запись RegisterAbility
+type AbilityInfo struct {
+	ID                string
+	Name              i18n.Message // название для игрока
+	New               func() Ability
+	Weight            int      // относительная частота при случайной выдаче, 0 - способность случайно не выдается
+	RequiresTarget    bool     // нужна ли клетка-цель
+	RequiresDirection bool     // нужно ли к клетке направление
+	OwnBoard          bool     // цель выбирается на своем поле, а не на поле противника
+	Aliases           []string // названия, под которыми способность попадала в сохранения первой версии
 }
@@ -0,0 +1,152 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Validate проверяет, что состояние партии не противоречит само себе: доски совпадают
+// с правилами, клетки сетки с кораблями, а текущий игрок и победитель существуют.
+// Вызывается после загрузки, поэтому не опирается на EnemyBoard и CurrentPlayer как указатели
+func (g *Game) Validate() error {
+	var problems []string
+
+	if err := g.Rules.Validate(); err != nil {
+		problems = append(problems, "правила: "+err.Error())
+	}
+
+	if g.Player1 == nil || g.Player2 == nil {
+		return fmt.Errorf("%w: в партии нет игроков", ErrInvalidGame)
+	}
+	if g.Player1.Name == "" || g.Player2.Name == "" || g.Player1.Name == g.Player2.Name {
+		problems = append(problems, "у игроков должны быть разные непустые имена")
+	}
+
+	for _, p := range []*Player{g.Player1, g.Player2} {
+		if p.MyBoard == nil {
+			problems = append(problems, fmt.Sprintf("у игрока %s нет поля", p.Name))
+			continue
+		}
+		for _, problem := range p.MyBoard.validate(g.Rules) {
+			problems = append(problems, fmt.Sprintf("поле игрока %s: %s", p.Name, problem))
+		}
+	}
+
+	if g.CurrentPlayer == nil || g.playerByName(g.CurrentPlayer.Name) == nil {
+		problems = append(problems, "текущий игрок не совпадает ни с одним из игроков")
+	}
+	if g.Winner != "" && g.playerByName(g.Winner) == nil {
+		problems = append(problems, fmt.Sprintf("победитель %q не участвует в партии", g.Winner))
+	}
+	if g.Winner == "" {
+		// иначе партия ждала бы хода, которого не может быть: бот не нашел бы куда стрелять
+		for _, p := range []*Player{g.Player1, g.Player2} {
+			if p.MyBoard != nil && len(p.MyBoard.Ships) > 0 && p.MyBoard.AllShipSunk() {
+				problems = append(problems, fmt.Sprintf("флот игрока %s потоплен, но победитель не указан", p.Name))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidGame, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// validate возвращает описания всех расхождений доски с правилами и между сеткой и кораблями
+func (b *Board) validate(rules Rules) []string {
+	var problems []string
+
+	if len(b.Grid) != rules.Height {
+		return []string{fmt.Sprintf("в сетке %d строк вместо %d", len(b.Grid), rules.Height)}
+	}
+	for x, row := range b.Grid {
+		if len(row) != rules.Width {
+			return []string{fmt.Sprintf("в строке %d сетки %d клеток вместо %d", x, len(row), rules.Width)}
+		}
+		for y, cell := range row {
+			if cell < EmptyCell || cell > HitCell {
+				problems = append(problems, fmt.Sprintf("неизвестное состояние клетки (%d, %d): %d", x, y, cell))
+			}
+		}
+	}
+
+	sizes := make([]int, len(b.Ships))
+	owner := map[Point]int{}
+	for i, ship := range b.Ships {
+		sizes[i] = ship.Size
+		if ship.Size < 1 || len(ship.Position) != ship.Size {
+			problems = append(problems, fmt.Sprintf("корабль %d: размер %d не совпадает с числом клеток %d", i, ship.Size, len(ship.Position)))
+			continue
+		}
+
+		hits := 0
+		for j, p := range ship.Position {
+			if j > 0 && p != shipSegment(ship.Position[0], j, ship.IsVertical) {
+				problems = append(problems, fmt.Sprintf("корабль %d: клетки не образуют прямую линию", i))
+				break
+			}
+			if !b.IsValidPoint(p) {
+				problems = append(problems, fmt.Sprintf("корабль %d: клетка (%d, %d) вне поля", i, p.X, p.Y))
+				break
+			}
+			if other, ok := owner[p]; ok {
+				problems = append(problems, fmt.Sprintf("корабли %d и %d занимают клетку (%d, %d)", other, i, p.X, p.Y))
+			}
+			owner[p] = i
+
+			switch b.Grid[p.X][p.Y] {
+			case HitCell:
+				hits++
+			case ShipCell:
+			default:
+				problems = append(problems, fmt.Sprintf("корабль %d: клетка (%d, %d) на сетке не отмечена как корабль", i, p.X, p.Y))
+			}
+		}
+
+		if ship.Hits != hits {
+			problems = append(problems, fmt.Sprintf("корабль %d: записано попаданий %d, а подбитых клеток %d", i, ship.Hits, hits))
+		}
+		if ship.IsSunk != (hits == ship.Size) {
+			problems = append(problems, fmt.Sprintf("корабль %d: отметка о потоплении не совпадает с попаданиями", i))
+		}
+	}
+
+	for i, p := range b.Shields {
+		if !b.IsValidPoint(p) || b.Grid[p.X][p.Y] != ShipCell || contains(b.Shields[:i], p) {
+			problems = append(problems, fmt.Sprintf("щит в (%d, %d) стоит не на целом сегменте корабля", p.X, p.Y))
+		}
+	}
+	for i, p := range b.Decoys {
+		_, onShip := owner[p]
+		if !b.IsValidPoint(p) || onShip || contains(b.Decoys[:i], p) {
+			problems = append(problems, fmt.Sprintf("ложная цель в (%d, %d) стоит не в пустой клетке", p.X, p.Y))
+		} else if cell := b.Grid[p.X][p.Y]; cell != EmptyCell && cell != HitCell {
+			problems = append(problems, fmt.Sprintf("ложная цель в (%d, %d) стоит в клетке %s", p.X, p.Y, cell))
+		}
+	}
+
+	for x, row := range b.Grid {
+		for y, cell := range row {
+			p := Point{X: x, Y: y}
+			if _, ok := owner[p]; !ok && (cell == ShipCell || cell == HitCell && !contains(b.Decoys, p)) {
+				problems = append(problems, fmt.Sprintf("клетка (%d, %d) отмечена как корабль, но корабля в ней нет", x, y))
+			}
+		}
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+	if fmt.Sprint(sizes) != fmt.Sprint(rules.FleetSizes()) {
+		problems = append(problems, fmt.Sprintf("флот %v не совпадает с правилами %v", sizes, rules.FleetSizes()))
+	}
+
+	return problems
+}
+
+func shipSegment(start Point, i int, vertical bool) Point {
+	if vertical {
+		return Point{X: start.X + i, Y: start.Y}
+	}
+	return Point{X: start.X, Y: start.Y + i}
+}
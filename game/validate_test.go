@@ -0,0 +1,60 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+// shootEverything обстреливает все клетки поля игрока p от имени противника
+func shootEverything(t *testing.T, g *Game, p *Player) {
+	t.Helper()
+	board := p.MyBoard
+	for x := range board.Grid {
+		for y := range board.Grid[x] {
+			if cell := board.Grid[x][y]; cell == MissCell || cell == HitCell {
+				continue
+			}
+			if _, err := board.Attack(&Point{X: x, Y: y}, g.Opponent(p)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestDecodeRejectsSunkFleetWithoutWinner(t *testing.T) {
+	for _, level := range []Difficulty{Easy, Medium, Hard} {
+		g, err := NewGame(Options{Rules: DefaultRules(), Difficulty: level, Seed: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		shootEverything(t, g, g.Player1)
+		g.CurrentPlayer = g.Player2
+
+		code, err := EncodeGameCode(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := DecodeGameCode(code); !errors.Is(err, ErrInvalidGame) {
+			t.Errorf("%s: DecodeGameCode вернул %v, ожидалась ErrInvalidGame", level, err)
+		}
+	}
+}
+
+func TestBotWithoutTargets(t *testing.T) {
+	for _, level := range []Difficulty{Easy, Medium, Hard} {
+		g, err := NewGame(Options{Rules: DefaultRules(), Difficulty: level, Seed: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		shootEverything(t, g, g.Player1)
+		g.CurrentPlayer = g.Player2
+		g.Player2.Strategy = restoreStrategy(level, g.Player1.MyBoard)
+
+		if _, err := g.HandleComputerTurn(); !errors.Is(err, ErrNoTargets) {
+			t.Errorf("%s: ход бота вернул %v, ожидалась ErrNoTargets", level, err)
+		}
+		if _, err := g.Player2.Strategy.NextSalvo(g, g.Player2, 3); !errors.Is(err, ErrNoTargets) {
+			t.Errorf("%s: залп бота вернул %v, ожидалась ErrNoTargets", level, err)
+		}
+	}
+}
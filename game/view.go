@@ -0,0 +1,119 @@
+package game
+
+import "sea_battle/i18n"
+
+// BoardView - поле в том виде, в котором его видит конкретный игрок
+type BoardView struct {
+	Grid    [][]CellState `json:"grid"`
+	Ships   []Ship        `json:"ships"`
+	Shields []Point       `json:"shields,omitempty"` // только на своем поле
+	Decoys  []Point       `json:"decoys,omitempty"`  // только на своем поле
+}
+
+type PlayerView struct {
+	Name            string       `json:"name"`
+	Board           BoardView    `json:"board"`
+	Abilities       []AbilityDTO `json:"abilities,omitempty"`
+	HasDoubleDamage bool         `json:"has_double_damage,omitempty"`
+	ShipsLeft       int          `json:"ships_left"`
+}
+
+// GameView - проекция партии для одного игрока: свое поле целиком, чужое - только результаты выстрелов
+type GameView struct {
+	Me            PlayerView `json:"me"`
+	Enemy         PlayerView `json:"enemy"`
+	CurrentPlayer string     `json:"current_player"`
+	IsMyTurn      bool       `json:"is_my_turn"`
+	ShotsPerTurn  int        `json:"shots_per_turn"` // размер залпа игрока, в классическом режиме 1
+	Rules         Rules      `json:"rules"`
+	Winner        string     `json:"winner,omitempty"`
+	Seed          uint64     `json:"seed,omitempty"` // открывается после окончания партии: по зерну видна расстановка бота
+	Difficulty    Difficulty `json:"difficulty,omitempty"`
+}
+
+// NewGameView строит проекцию партии для игрока viewer; названия способностей переводятся на язык lang
+func NewGameView(g *Game, viewer *Player, lang i18n.Lang) *GameView {
+	enemy := g.Opponent(viewer)
+
+	abilities := make([]AbilityDTO, len(viewer.Abilities))
+	for i, ability := range viewer.Abilities {
+		abilities[i] = AbilityInfoOf(ability).DTO(lang)
+	}
+
+	view := &GameView{
+		Me: PlayerView{
+			Name:            viewer.Name,
+			Board:           ownBoardView(viewer.MyBoard),
+			Abilities:       abilities,
+			HasDoubleDamage: viewer.HasDoubleDamage,
+			ShipsLeft:       viewer.MyBoard.ShipsLeft(),
+		},
+		Enemy: PlayerView{
+			Name:      enemy.Name,
+			Board:     enemyBoardView(enemy.MyBoard),
+			ShipsLeft: enemy.MyBoard.ShipsLeft(),
+		},
+		CurrentPlayer: g.CurrentPlayer.Name,
+		IsMyTurn:      g.CurrentPlayer == viewer,
+		ShotsPerTurn:  g.ShotsPerTurn(viewer),
+		Rules:         g.Rules,
+		Winner:        g.Winner,
+	}
+
+	if g.IsOver() {
+		view.Seed = g.Seed
+	}
+
+	if enemy.Strategy != nil {
+		view.Difficulty = enemy.Strategy.Difficulty()
+	}
+	return view
+}
+
+func ownBoardView(b *Board) BoardView {
+	view := BoardView{Grid: newGrid(b.Rules.Width, b.Rules.Height), Ships: make([]Ship, len(b.Ships))}
+	for i := range b.Grid {
+		copy(view.Grid[i], b.Grid[i])
+	}
+	copy(view.Ships, b.Ships)
+	view.Shields = append([]Point(nil), b.Shields...)
+	view.Decoys = append([]Point(nil), b.Decoys...)
+	return view
+}
+
+// enemyBoardView скрывает целые клетки кораблей и оставляет только потопленные корабли
+func enemyBoardView(b *Board) BoardView {
+	view := BoardView{Grid: newGrid(b.Rules.Width, b.Rules.Height), Ships: []Ship{}}
+	for i := range b.Grid {
+		for j, cell := range b.Grid[i] {
+			if cell == ShipCell {
+				cell = EmptyCell
+			}
+			view.Grid[i][j] = cell
+		}
+	}
+
+	for _, ship := range b.Ships {
+		if ship.IsSunk {
+			view.Ships = append(view.Ships, ship)
+		}
+	}
+	return view
+}
+
+func (b *Board) ShipsLeft() int {
+	left := 0
+	for i := range b.Ships {
+		if !b.Ships[i].IsSunk {
+			left++
+		}
+	}
+	return left
+}
+
+func (g *Game) Opponent(p *Player) *Player {
+	if p == g.Player1 {
+		return g.Player2
+	}
+	return g.Player1
+}
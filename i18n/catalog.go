@@ -0,0 +1,190 @@
+package i18n
+
+// catalog[язык][ID] - шаблоны сообщений. Новый язык добавляется отдельным набором с теми же ID;
+// недостающие в нем сообщения берутся из языка по умолчанию
+var catalog = map[Lang]map[string]string{
+	RU: {
+		"attack.miss":          "Промах! Ход переходит",
+		"attack.blocked":       "Выстрел отражен щитом! Ход переходит",
+		"attack.hit":           "Попадание! Вы ходите еще раз",
+		"attack.sunk":          "Корабль потоплен! Вы ходите еще раз и вам добавлена способность!",
+		"attack.double_damage": "Двойной урон! Подбит соседний сегмент. Вы ходите еще раз",
+		"game.over":            "Игра окончена! Победитель: {winner}",
+		"salvo.done":           "Залп: попаданий {hits}, потоплено кораблей {sunk}. Ход переходит",
+
+		"opponent.miss":    "Соперник промахнулся! Ваш ход",
+		"opponent.blocked": "Ваш щит отразил выстрел соперника! Ваш ход",
+		"opponent.hit":     "Соперник попал и ходит еще раз",
+		"opponent.sunk":    "Соперник потопил ваш корабль и ходит еще раз",
+
+		"lobby.seat1": "Игрок 1",
+		"lobby.seat2": "Игрок 2",
+
+		"ability.artillery_strike":      "Артиллерийский удар",
+		"ability.scanner":               "Сканнер",
+		"ability.double_damage":         "Двойной урон",
+		"ability.torpedo":               "Торпеда",
+		"ability.air_strike":            "Авиаудар",
+		"ability.carpet_bomb":           "Ковровая бомбардировка",
+		"ability.diagonal_strike":       "Удар по диагонали",
+		"ability.shield":                "Щит",
+		"ability.decoy":                 "Ложная цель",
+		"ability.repair":                "Ремонт",
+		"ability.artillery_strike.done": "Артиллерийский удар нанесен по ({x}, {y})",
+		"ability.artillery_strike.none": "Нет целей для артиллерийского удара",
+		"ability.scanner.done":          "Сканирование области 3x3 в точке ({x}, {y}). Обнаружено {count} сегментов кораблей",
+		"ability.double_damage.done":    "Следующее попадание подобьет еще и соседний сегмент корабля!",
+		"ability.torpedo.hit":           "Торпеда попала в корабль в ({x}, {y})",
+		"ability.torpedo.blocked":       "Торпеду остановил щит в ({x}, {y})",
+		"ability.torpedo.miss":          "Торпеда прошла через все поле и никого не задела",
+		"ability.strike.done":           "{name}: обстреляно клеток - {cells}, попаданий - {hits}",
+		"ability.shield.done":           "Сегмент в ({x}, {y}) прикрыт щитом от следующего попадания",
+		"ability.decoy.done":            "Ложная цель поставлена в ({x}, {y})",
+		"ability.repair.done":           "Сегмент в ({x}, {y}) отремонтирован",
+
+		"fleet.invalid_size":  "корабль №{n}: недопустимый размер {size}",
+		"fleet.no_position":   "корабль №{n}: не указана стартовая позиция для корабля размером {size}",
+		"fleet.out_of_bounds": "корабль №{n}: {size}-палубный корабль в ({x}, {y}) выходит за пределы поля",
+		"fleet.touching":      "корабль №{n}: {size}-палубный корабль в ({x}, {y}) соприкасается или пересекается с другим",
+		"fleet.overlapping":   "корабль №{n}: {size}-палубный корабль в ({x}, {y}) пересекается с другим",
+		"fleet.extra_ship":    "корабль №{n}: лишний {size}-палубный корабль",
+		"fleet.missing_ship":  "не хватает {size}-палубного корабля",
+
+		"server.game_created":        "Новая игра успешно создана",
+		"server.game_created_manual": "Новая игра (ручная расстановка) успешно создана",
+		"server.game_saved":          "Игра успешно сохранена",
+		"server.game_loaded":         "Игра успешно загружена",
+		"server.save_deleted":        "Сохранение удалено",
+		"server.game_imported":       "Позиция успешно загружена",
+
+		"error.bad_parameter":        "Ошибка в параметрах запроса",
+		"error.method_not_allowed":   "Метод не разрешен",
+		"error.match_not_found":      "Партия не найдена",
+		"error.session_not_found":    "Игра не найдена: начните новую",
+		"error.too_many_sessions":    "Сервер занят: слишком много одновременных игр, попробуйте позже",
+		"error.match_full":           "В партии уже два игрока",
+		"error.bad_token":            "Неверный токен игрока",
+		"error.game_not_started":     "Игра еще не началась",
+		"error.game_started":         "Игра уже началась",
+		"error.not_against_bot":      "Код описывает партию двух людей, а не игру против бота",
+		"error.unknown_message":      "Неизвестный тип сообщения",
+		"error.game_over":            "Игра окончена",
+		"error.not_your_turn":        "Сейчас не ваш ход",
+		"error.not_bot_turn":         "Сейчас ходит не бот",
+		"error.invalid_fleet":        "Флот не соответствует правилам",
+		"error.out_of_bounds":        "Клетка вне поля",
+		"error.already_shot":         "По этой клетке уже стреляли",
+		"error.no_such_ability":      "У вас нет такой способности или она не существует",
+		"error.wrong_mode":           "Такой ход не подходит к режиму партии",
+		"error.wrong_shot_count":     "Неверное число выстрелов в залпе",
+		"error.target_required":      "Способности нужна цель: укажите координаты x и y",
+		"error.invalid_target":       "Недопустимая цель способности",
+		"error.ships_touching":       "Корабли соприкасаются",
+		"error.ships_overlap":        "Корабли пересекаются",
+		"error.invalid_rules":        "Недопустимые правила",
+		"error.unknown_difficulty":   "Неизвестный уровень сложности",
+		"error.no_history":           "В этой партии не сохранен журнал ходов",
+		"error.invalid_step":         "Неверный номер хода",
+		"error.save_not_found":       "Сохранение не найдено",
+		"error.invalid_slot":         "Недопустимое имя слота: разрешены буквы, цифры, '-' и '_' (до 64 символов)",
+		"error.save_tampered":        "Сохранение изменено или подписано другим ключом",
+		"error.unknown_save_version": "Сохранение сделано неизвестной версией игры",
+		"error.save_corrupt":         "Сохранение повреждено",
+		"error.bad_code":             "Неверный код позиции",
+		"error.unknown_ability":      "Неизвестная способность",
+		"error.invalid_game":         "Партия не прошла проверку",
+		"error.internal":             "Внутренняя ошибка сервера",
+	},
+	EN: {
+		"attack.miss":          "Miss! The turn passes",
+		"attack.blocked":       "The shot was blocked by a shield! The turn passes",
+		"attack.hit":           "Hit! You go again",
+		"attack.sunk":          "Ship sunk! You go again and get a new ability!",
+		"attack.double_damage": "Double damage! A neighbouring segment is hit too. You go again",
+		"game.over":            "Game over! Winner: {winner}",
+		"salvo.done":           "Salvo: {hits} hits, {sunk} ships sunk. The turn passes",
+
+		"opponent.miss":    "Your opponent missed! Your turn",
+		"opponent.blocked": "Your shield blocked the opponent's shot! Your turn",
+		"opponent.hit":     "Your opponent hit and goes again",
+		"opponent.sunk":    "Your opponent sank your ship and goes again",
+
+		"lobby.seat1": "Player 1",
+		"lobby.seat2": "Player 2",
+
+		"ability.artillery_strike":      "Artillery strike",
+		"ability.scanner":               "Scanner",
+		"ability.double_damage":         "Double damage",
+		"ability.torpedo":               "Torpedo",
+		"ability.air_strike":            "Air strike",
+		"ability.carpet_bomb":           "Carpet bomb",
+		"ability.diagonal_strike":       "Diagonal strike",
+		"ability.shield":                "Shield",
+		"ability.decoy":                 "Decoy",
+		"ability.repair":                "Repair",
+		"ability.artillery_strike.done": "Artillery strike fired at ({x}, {y})",
+		"ability.artillery_strike.none": "No targets left for an artillery strike",
+		"ability.scanner.done":          "Scanned the 3x3 area at ({x}, {y}). Found {count} ship segments",
+		"ability.double_damage.done":    "Your next hit will also damage a neighbouring segment!",
+		"ability.torpedo.hit":           "The torpedo hit a ship at ({x}, {y})",
+		"ability.torpedo.blocked":       "The torpedo was stopped by a shield at ({x}, {y})",
+		"ability.torpedo.miss":          "The torpedo crossed the whole board without hitting anything",
+		"ability.strike.done":           "{name}: {cells} cells shelled, {hits} hits",
+		"ability.shield.done":           "The segment at ({x}, {y}) is shielded from the next hit",
+		"ability.decoy.done":            "A decoy is placed at ({x}, {y})",
+		"ability.repair.done":           "The segment at ({x}, {y}) is repaired",
+
+		"fleet.invalid_size":  "ship #{n}: invalid size {size}",
+		"fleet.no_position":   "ship #{n}: no start position for a ship of size {size}",
+		"fleet.out_of_bounds": "ship #{n}: the {size}-deck ship at ({x}, {y}) goes off the board",
+		"fleet.touching":      "ship #{n}: the {size}-deck ship at ({x}, {y}) touches or overlaps another ship",
+		"fleet.overlapping":   "ship #{n}: the {size}-deck ship at ({x}, {y}) overlaps another ship",
+		"fleet.extra_ship":    "ship #{n}: one {size}-deck ship too many",
+		"fleet.missing_ship":  "a {size}-deck ship is missing",
+
+		"server.game_created":        "New game created",
+		"server.game_created_manual": "New game with your own placement created",
+		"server.game_saved":          "Game saved",
+		"server.game_loaded":         "Game loaded",
+		"server.save_deleted":        "Save deleted",
+		"server.game_imported":       "Position loaded",
+
+		"error.bad_parameter":        "Invalid request parameters",
+		"error.method_not_allowed":   "Method not allowed",
+		"error.match_not_found":      "Match not found",
+		"error.session_not_found":    "Game not found: start a new one",
+		"error.too_many_sessions":    "The server is busy: too many games at once, try again later",
+		"error.match_full":           "The match already has two players",
+		"error.bad_token":            "Invalid player token",
+		"error.game_not_started":     "The game has not started yet",
+		"error.game_started":         "The game has already started",
+		"error.not_against_bot":      "The code describes a game between two people, not a game against the bot",
+		"error.unknown_message":      "Unknown message type",
+		"error.game_over":            "The game is over",
+		"error.not_your_turn":        "It is not your turn",
+		"error.not_bot_turn":         "It is not the bot's turn",
+		"error.invalid_fleet":        "The fleet does not match the rules",
+		"error.out_of_bounds":        "The cell is outside the board",
+		"error.already_shot":         "This cell has already been shot at",
+		"error.no_such_ability":      "You do not have this ability or it does not exist",
+		"error.wrong_mode":           "This move does not fit the game mode",
+		"error.wrong_shot_count":     "Wrong number of shots in the salvo",
+		"error.target_required":      "This ability needs a target: pass the x and y coordinates",
+		"error.invalid_target":       "Invalid ability target",
+		"error.ships_touching":       "Ships touch each other",
+		"error.ships_overlap":        "Ships overlap",
+		"error.invalid_rules":        "Invalid rules",
+		"error.unknown_difficulty":   "Unknown difficulty level",
+		"error.no_history":           "This game has no move history",
+		"error.invalid_step":         "Invalid move number",
+		"error.save_not_found":       "Save not found",
+		"error.invalid_slot":         "Invalid slot name: use letters, digits, '-' and '_' (up to 64 characters)",
+		"error.save_tampered":        "The save was modified or signed with a different key",
+		"error.unknown_save_version": "The save was made by an unknown version of the game",
+		"error.save_corrupt":         "The save is corrupt",
+		"error.bad_code":             "Invalid position code",
+		"error.unknown_ability":      "Unknown ability",
+		"error.invalid_game":         "The game failed validation",
+		"error.internal":             "Internal server error",
+	},
+}
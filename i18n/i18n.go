@@ -0,0 +1,96 @@
+// Package i18n - каталог сообщений для игроков на нескольких языках.
+// Игровая логика возвращает Message - ID сообщения и параметры, а текст на нужном языке
+// получается уже при ответе клиенту через Lang.Format
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Lang string
+
+const (
+	RU Lang = "ru"
+	EN Lang = "en"
+
+	Default = RU
+)
+
+// Params - значения для подстановки в шаблон сообщения вместо {имя}
+type Params map[string]any
+
+type Message struct {
+	ID     string `json:"id"`
+	Params Params `json:"params,omitempty"`
+}
+
+// M - сообщение без параметров
+func M(id string) Message {
+	return Message{ID: id}
+}
+
+// Langs возвращает поддерживаемые языки
+func Langs() []Lang {
+	langs := make([]Lang, 0, len(catalog))
+	for lang := range catalog {
+		langs = append(langs, lang)
+	}
+	sort.Slice(langs, func(i, j int) bool { return langs[i] < langs[j] })
+	return langs
+}
+
+// Parse возвращает язык по коду вида "en" или "en-US"
+func Parse(code string) (Lang, bool) {
+	base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(code)), "-")
+	lang := Lang(base)
+	_, ok := catalog[lang]
+	return lang, ok
+}
+
+// Negotiate выбирает язык по заголовку Accept-Language с учетом весов q, иначе Default
+func Negotiate(acceptLanguage string) Lang {
+	best, bestQ := Default, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		code, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		if lang, ok := Parse(code); ok && q > bestQ {
+			best, bestQ = lang, q
+		}
+	}
+	return best
+}
+
+// Format переводит сообщение. Если перевода нет, берется язык по умолчанию, а если нет и его - сам ID
+func (l Lang) Format(m Message) string {
+	text, ok := catalog[l][m.ID]
+	if !ok {
+		text, ok = catalog[Default][m.ID]
+	}
+	if !ok {
+		return m.ID
+	}
+
+	for name, value := range m.Params {
+		if nested, ok := value.(Message); ok {
+			value = l.Format(nested)
+		}
+		text = strings.ReplaceAll(text, "{"+name+"}", fmt.Sprint(value))
+	}
+	return text
+}
+
+// Text переводит сообщение без параметров
+func (l Lang) Text(id string) string {
+	return l.Format(M(id))
+}